@@ -0,0 +1,146 @@
+package main
+
+import (
+	"log/slog"
+	"solar-scope/database"
+	"solar-scope/internal/apierror"
+	"solar-scope/internal/auth"
+	"solar-scope/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// registerAdminRoutes, API anahtarı ve tenant yönetimi rotalarını ekler.
+func registerAdminRoutes(apiV1 fiber.Router) {
+	// API anahtarı yönetimi (yalnızca admin)
+	adminGroup := apiV1.Group("/admin", auth.Require(auth.RoleAdmin))
+
+	adminGroup.Post("/keys", func(c *fiber.Ctx) error {
+		var req struct {
+			Name     string `json:"name"`
+			Role     string `json:"role"`
+			TenantID uint   `json:"tenant_id"`
+		}
+		if err := c.BodyParser(&req); err != nil || req.Name == "" || !auth.ValidRole(req.Role) {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid key payload, name and a role of viewer, operator or admin are required")
+		}
+
+		// Tenant admin'leri yalnızca kendi tenant'larına anahtar oluşturabilir
+		principal := auth.PrincipalFrom(c)
+		if !principal.IsSystem() {
+			req.TenantID = principal.TenantID
+		} else if req.TenantID != 0 {
+			t, err := database.GetTenant(req.TenantID)
+			if err != nil {
+				slog.ErrorContext(c.UserContext(), "error retrieving tenant", "error", err)
+				return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to create API key")
+			}
+			if t == nil {
+				return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Tenant not found")
+			}
+		}
+
+		plainKey, err := auth.GenerateKey()
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "error generating API key", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to create API key")
+		}
+		key := models.APIKey{
+			TenantID: req.TenantID,
+			Name:     req.Name,
+			Prefix:   auth.DisplayPrefix(plainKey),
+			KeyHash:  auth.HashKey(plainKey),
+			Role:     req.Role,
+		}
+		if err := database.CreateAPIKey(&key); err != nil {
+			slog.ErrorContext(c.UserContext(), "error saving API key", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to create API key")
+		}
+
+		// Anahtarın kendisi yalnızca bu yanıtta gösterilir
+		return c.Status(201).JSON(fiber.Map{
+			"key":     plainKey,
+			"api_key": key,
+		})
+	})
+
+	adminGroup.Get("/keys", func(c *fiber.Ctx) error {
+		keys, err := database.ListAPIKeys(auth.PrincipalFrom(c).TenantID)
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "error listing API keys", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to list API keys")
+		}
+		return c.Status(200).JSON(keys)
+	})
+
+	adminGroup.Delete("/keys/:id", func(c *fiber.Ctx) error {
+		found, err := database.DeleteAPIKey(auth.PrincipalFrom(c).TenantID, c.Params("id"))
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "error deleting API key", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to revoke API key")
+		}
+		if !found {
+			return apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "API key not found")
+		}
+		return c.Status(200).JSON(fiber.Map{
+			"status":  "success",
+			"message": "API key revoked",
+		})
+	})
+
+	// Tenant yönetimi (yalnızca sistem admin'i)
+	tenantsGroup := adminGroup.Group("/tenants", auth.RequireSystem())
+
+	tenantsGroup.Post("/", func(c *fiber.Ctx) error {
+		var t models.Tenant
+		if err := c.BodyParser(&t); err != nil || t.Name == "" || t.Slug == "" || t.LabelValue == "" {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid tenant payload, name, slug and label_value are required")
+		}
+		if t.LabelName == "" {
+			t.LabelName = "ilce" // Varsayılan tenant etiketi
+		}
+		t.ID = 0
+		if err := database.CreateTenant(&t); err != nil {
+			slog.ErrorContext(c.UserContext(), "error creating tenant", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to create tenant")
+		}
+		return c.Status(201).JSON(t)
+	})
+
+	// Tenant'ın kullanılmayan session'larının ömrünü güncelle
+	tenantsGroup.Patch("/:id", func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil || id <= 0 {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid tenant ID")
+		}
+		var payload struct {
+			SessionTTLSeconds *int64 `json:"session_ttl_seconds"`
+		}
+		if err := c.BodyParser(&payload); err != nil || payload.SessionTTLSeconds == nil || *payload.SessionTTLSeconds < 0 {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid tenant payload, session_ttl_seconds must be zero or positive")
+		}
+		found, err := database.SetTenantSessionTTL(uint(id), *payload.SessionTTLSeconds)
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "error updating tenant", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to update tenant")
+		}
+		if !found {
+			return apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "Tenant not found")
+		}
+		tenant, err := database.GetTenant(uint(id))
+		if err != nil || tenant == nil {
+			slog.ErrorContext(c.UserContext(), "error retrieving tenant", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve tenant")
+		}
+		return c.Status(200).JSON(tenant)
+	})
+
+	tenantsGroup.Get("/", func(c *fiber.Ctx) error {
+		tenants, err := database.ListTenants()
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "error listing tenants", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to list tenants")
+		}
+		return c.Status(200).JSON(tenants)
+	})
+}
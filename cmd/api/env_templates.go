@@ -0,0 +1,208 @@
+package main
+
+import (
+	"log/slog"
+	"solar-scope/database"
+	"solar-scope/internal/apierror"
+	"solar-scope/internal/auth"
+	"solar-scope/internal/client"
+	"solar-scope/internal/envfile"
+	"solar-scope/models"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// renderRequest, bir env şablonunun sahaya göre doldurulması isteğidir.
+// Values, sahanın parametrelerini geçersiz kılar.
+type renderRequest struct {
+	SiteID *uint                  `json:"site_id"`
+	Values map[string]interface{} `json:"values"`
+	TTL    string                 `json:"ttl"`
+}
+
+// renderedTemplate, doldurulmuş ve doğrulanmış bir env dosyasıdır.
+type renderedTemplate struct {
+	TemplateID uint              `json:"template_id"`
+	Name       string            `json:"name"`
+	Version    int               `json:"version"`
+	SiteID     *uint             `json:"site_id"`
+	Content    string            `json:"content"`
+	Problems   []envfile.Problem `json:"problems"`
+}
+
+// registerEnvTemplateRoutes, sürümlü env şablonlarının rotalarını ekler.
+func registerEnvTemplateRoutes(apiV1 fiber.Router, viewer, operator fiber.Handler, sfClient *client.SolarForecasterClient) {
+	templatesGroup := apiV1.Group("/env-templates", viewer)
+
+	templatesGroup.Get("/", func(c *fiber.Ctx) error {
+		templates, err := database.ListEnvTemplates(c.UserContext(), auth.PrincipalFrom(c).TenantID)
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "error listing env templates", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to list env templates")
+		}
+		return c.Status(200).JSON(templates)
+	})
+
+	// Aynı adla kaydedilen şablon yeni bir sürüm olur; eski sürümler korunur
+	templatesGroup.Post("/", operator, func(c *fiber.Ctx) error {
+		var template models.EnvTemplate
+		if err := c.BodyParser(&template); err != nil || template.Name == "" || template.Content == "" {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid env template payload, name and content are required")
+		}
+		if err := envfile.CheckTemplate(template.Content); err != nil {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, err.Error())
+		}
+
+		// Sistem kimlikleri tenant_id ile şablon oluşturabilir, diğerleri kendi tenant'ına
+		principal := auth.PrincipalFrom(c)
+		if !principal.IsSystem() {
+			template.TenantID = principal.TenantID
+		}
+		if template.TenantID == 0 {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "tenant_id is required")
+		}
+		template.ID = 0
+		template.CreatedBy = principal.Subject
+		if err := database.CreateEnvTemplate(c.UserContext(), &template); err != nil {
+			slog.ErrorContext(c.UserContext(), "error creating env template", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to create env template")
+		}
+		return c.Status(201).JSON(template)
+	})
+
+	templatesGroup.Get("/:id", func(c *fiber.Ctx) error {
+		template, err := findEnvTemplate(c)
+		if template == nil {
+			return err
+		}
+		return c.Status(200).JSON(template)
+	})
+
+	templatesGroup.Get("/:id/versions", func(c *fiber.Ctx) error {
+		template, err := findEnvTemplate(c)
+		if template == nil {
+			return err
+		}
+		versions, err := database.ListEnvTemplateVersions(c.UserContext(), template)
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "error listing env template versions", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to list env template versions")
+		}
+		return c.Status(200).JSON(versions)
+	})
+
+	// Şablonu doldurup doğrular, session oluşturmaz
+	templatesGroup.Post("/:id/render", func(c *fiber.Ctx) error {
+		template, err := findEnvTemplate(c)
+		if template == nil {
+			return err
+		}
+		var req renderRequest
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&req); err != nil {
+				return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid render payload")
+			}
+		}
+		rendered, err := renderEnvTemplate(c, template, req)
+		if rendered == nil {
+			return err
+		}
+		if len(rendered.Problems) > 0 {
+			return apierror.WriteDetails(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Rendered env file is invalid", rendered.Problems)
+		}
+		return c.Status(200).JSON(rendered)
+	})
+
+	// Şablonu doldurur, doğrular ve forecaster'a yükleyerek session oluşturur
+	templatesGroup.Post("/:id/sessions", operator, func(c *fiber.Ctx) error {
+		template, err := findEnvTemplate(c)
+		if template == nil {
+			return err
+		}
+		var req renderRequest
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&req); err != nil {
+				return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid render payload")
+			}
+		}
+		rendered, err := renderEnvTemplate(c, template, req)
+		if rendered == nil {
+			return err
+		}
+		if len(rendered.Problems) > 0 {
+			return apierror.WriteDetails(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Rendered env file is invalid", rendered.Problems)
+		}
+
+		session := &models.ForecasterSession{
+			TenantID:   template.TenantID,
+			SiteID:     rendered.SiteID,
+			TemplateID: &template.ID,
+		}
+		if session.TTLSeconds, err = parseSessionTTL(req.TTL); err != nil {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, err.Error())
+		}
+		return uploadSession(c, sfClient, []byte(rendered.Content), session)
+	})
+}
+
+// findEnvTemplate, :id parametresindeki şablonu çağıranın tenant'ında arar.
+// Şablon bulunamazsa nil ve yazılmış hata yanıtını döner.
+func findEnvTemplate(c *fiber.Ctx) (*models.EnvTemplate, error) {
+	template, err := database.GetEnvTemplate(c.UserContext(), auth.PrincipalFrom(c).TenantID, c.Params("id"))
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "error retrieving env template", "error", err)
+		return nil, apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve env template")
+	}
+	if template == nil {
+		return nil, apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "Env template not found")
+	}
+	return template, nil
+}
+
+// renderEnvTemplate, şablonu doldurur ve doğrular. Değerler öncelik sırasıyla
+// istekten, sahanın parametrelerinden ve site_id ile site_name yerleşik yer
+// tutucularından gelir. Doldurulamazsa nil ve yazılmış hata yanıtını döner.
+func renderEnvTemplate(c *fiber.Ctx, template *models.EnvTemplate, req renderRequest) (*renderedTemplate, error) {
+	values := map[string]string{}
+	if req.SiteID != nil {
+		// Saha, şablonla aynı tenant'a ait olmalıdır
+		site, err := database.GetSite(template.TenantID, strconv.FormatUint(uint64(*req.SiteID), 10))
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "error retrieving site", "error", err)
+			return nil, apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve site")
+		}
+		if site == nil {
+			return nil, apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "Site not found")
+		}
+		siteValues, err := envfile.Stringify(site.Params)
+		if err != nil {
+			return nil, apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid site params: "+err.Error())
+		}
+		values["site_id"] = strconv.FormatUint(uint64(site.ID), 10)
+		values["site_name"] = site.Name
+		for key, value := range siteValues {
+			values[key] = value
+		}
+	}
+	overrides, err := envfile.Stringify(req.Values)
+	if err != nil {
+		return nil, apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, err.Error())
+	}
+	for key, value := range overrides {
+		values[key] = value
+	}
+
+	content, err := envfile.Render(template.Content, values)
+	if err != nil {
+		return nil, apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, err.Error())
+	}
+	return &renderedTemplate{
+		TemplateID: template.ID,
+		Name:       template.Name,
+		Version:    template.Version,
+		SiteID:     req.SiteID,
+		Content:    content,
+		Problems:   envfile.ValidateContent([]byte(content)),
+	}, nil
+}
@@ -1,40 +1,193 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"os/signal"
 	"solar-scope/database"
+	"solar-scope/internal/apierror"
+	"solar-scope/internal/auth"
 	"solar-scope/internal/client"
 	"solar-scope/internal/config"
+	"solar-scope/internal/envfile"
+	"solar-scope/internal/health"
+	"solar-scope/internal/idempotency"
+	"solar-scope/internal/logging"
+	"solar-scope/internal/metrics"
+	"solar-scope/internal/openapi"
+	"solar-scope/internal/provider"
+	"solar-scope/internal/quality"
+	"solar-scope/internal/requestctx"
+	"solar-scope/internal/sessions"
+	"solar-scope/internal/stream"
+	"solar-scope/internal/tenant"
+	"solar-scope/internal/tracing"
+	"solar-scope/internal/validate"
+	"solar-scope/models"
+	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 )
 
 func main() {
 	// Konfigürasyonu yükle
 	cfg := config.LoadConfig()
-	log.Printf("Config loaded: %+v", cfg)
+	logging.Setup(cfg.LogLevel)
+	slog.Info("config loaded", "config", cfg.String())
+
+	shutdownTracing, err := tracing.Setup(context.Background(), *cfg)
+	if err != nil {
+		fatal("error setting up tracing", "error", err)
+	}
 	database.Connect(*cfg)
 
-	vmClient, err := client.NewPrometheusClient(cfg.VictoriaMetricsURL)
+	srv, err := newServer(cfg)
 	if err != nil {
-		log.Fatalf("Error creating VictoriaMetrics client: %v", err)
+		fatal("error creating server", "error", err)
 	}
-	log.Println("VictoriaMetrics client created successfully:", vmClient)
 
-	sfClient := client.NewSolarForecasterClient(cfg.SolarForecasterURL)
+	slog.Info("starting server", "port", cfg.AppPort)
+
+	go func() {
+		if err := srv.app.Listen("0.0.0.0:" + cfg.AppPort); err != nil {
+			fatal("error starting server", "error", err)
+		}
+	}()
+
+	// SIGINT/SIGTERM gelene kadar bekle
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	<-ctx.Done()
+	stop()
+
+	slog.Info("shutdown signal received, draining", "timeout", cfg.ShutdownTimeout.String())
+	drainCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	// Açık SSE akışlarını kapat, aksi halde bağlantılar hiç boşalmaz
+	srv.hub.Close()
+
+	// Yeni istek kabul etme ve devam eden isteklerin bitmesini bekle
+	if err := srv.app.ShutdownWithContext(drainCtx); err != nil {
+		slog.Error("error shutting down server", "error", err)
+	}
+
+	// Arka planda devam eden tahmin kayıtlarını bekle
+	if err := database.WaitForSaves(drainCtx); err != nil {
+		slog.Error("error draining forecast saves", "error", err)
+	}
+
+	// Session senkronizasyonunu, temizliğini ve forecaster backend yoklamasını durdur
+	srv.close()
+
+	if err := database.Close(); err != nil {
+		slog.Error("error closing database", "error", err)
+	}
+
+	// Bekleyen span'leri gönder
+	if err := shutdownTracing(drainCtx); err != nil {
+		slog.Error("error flushing traces", "error", err)
+	}
+	slog.Info("server stopped")
+}
+
+// server, HTTP uygulaması ve kapanışta durdurulması gereken arka plan
+// bileşenleridir.
+type server struct {
+	app           *fiber.App
+	hub           *stream.Hub
+	sessionSyncer *sessions.Syncer
+	sessionReaper *sessions.Reaper
+	sfClient      *client.SolarForecasterClient
+}
+
+// close, session senkronizasyonunu, temizliğini ve forecaster backend
+// yoklamasını durdurur.
+func (s *server) close() {
+	s.sessionSyncer.Close()
+	s.sessionReaper.Close()
+	s.sfClient.Close()
+}
+
+// newServer, istemcileri kurar ve tüm rotaları kaydeder. Veritabanına
+// bağlanmaz; rotalar veritabanını yalnızca istek geldiğinde kullanır.
+func newServer(cfg *config.Config) (*server, error) {
+	vmClient, err := client.NewPrometheusClient(cfg.VictoriaMetricsURL, cfg.VMQueryTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create VictoriaMetrics client: %w", err)
+	}
+	slog.Info("VictoriaMetrics client created", "url", cfg.VictoriaMetricsURL)
+
+	sfClient, err := client.NewSolarForecasterClient(cfg.SolarForecasterURLs, client.Balancing{
+		Strategy:       cfg.ForecasterBalancer,
+		HealthInterval: cfg.ForecasterHealthInterval,
+	}, client.Resilience{
+		Timeout:          cfg.ForecasterTimeout,
+		Timeouts:         cfg.ForecasterTimeouts,
+		Attempts:         cfg.ForecasterRetryAttempts,
+		BaseDelay:        cfg.ForecasterRetryBaseDelay,
+		MaxDelay:         cfg.ForecasterRetryMaxDelay,
+		BreakerThreshold: cfg.ForecasterBreakerThreshold,
+		BreakerCooldown:  cfg.ForecasterBreakerCooldown,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SolarForecaster client: %w", err)
+	}
+	slog.Info("SolarForecaster client created", "urls", cfg.SolarForecasterURLs, "balancer", cfg.ForecasterBalancer)
 
-	log.Println("SolarForecaster client created successfully:", sfClient)
-	app := fiber.New()
+	// Yerel session kayıtlarını forecaster'daki listeyle düzenli olarak eşitle;
+	// aralık 0 ise döngü başlamaz, eşitleme yalnızca elle tetiklenir
+	sessionSyncer := sessions.NewSyncer(sfClient.SessionBackends, cfg.SessionSyncInterval)
 
-	app.Use(logger.New())
+	// Süresi dolan kullanılmayan session'ları forecaster'dan sil. SESSION_TTL
+	// 0 ise arka planda temizlik yapılmaz; elle tetiklenen temizlik yalnızca
+	// session ve tenant sürelerini uygular.
+	reapInterval := cfg.SessionReapInterval
+	if cfg.SessionTTL == 0 {
+		reapInterval = 0
+	}
+	sessionReaper := sessions.NewReaper(func(ctx context.Context, sessionID string) error {
+		_, err := sfClient.DeleteSession(ctx, sessionID)
+		return err
+	}, cfg.SessionTTL, reapInterval, cfg.SessionReapDryRun)
+
+	// Canlı panel akışı; tüm abonelere tek bir poller üzerinden dağıtılır
+	hub := stream.NewHub(vmClient, panelQuery, cfg.StreamInterval)
+
+	// saveForecast, tahmini arka planda kaydeder ve akış abonelerine bildirir
+	saveForecast := func(ctx context.Context, result map[string]interface{}, tenantID uint) {
+		database.SaveResultAsync(ctx, result, tenantID, func(forecast *models.Forecast) {
+			hub.PublishForecast(tenantID, forecast)
+		})
+	}
+	// Hazırlık kontrolleri; veritabanı ve VictoriaMetrics kritik kabul edilir
+	startedAt := time.Now()
+	checker := health.NewChecker(cfg.HealthCacheTTL, cfg.HealthCheckTimeout)
+	checker.Register("database", true, database.Ping)
+	checker.Register("victoriametrics", true, vmClient.Ping)
+	checker.Register("solar_forecaster", false, sfClient.Ping)
+	checker.Register("solar_forecaster_circuit", false, sfClient.CheckCircuit)
+
+	app := fiber.New(fiber.Config{
+		ErrorHandler: apierror.Handler,
+	})
+
+	app.Use(logging.Middleware())
+	app.Use(tracing.Middleware())
+	app.Use(requestctx.Middleware()) // İstemci bağlantıyı kapatınca upstream çağrılarını iptal eder
+	app.Use(metrics.Middleware())
 	app.Use(recover.New()) // Panik durumlarında uygulamanın çökmesini önler
 
+	// Servisin kendi metrikleri; Prometheus kazıyıcıları için kimlik doğrulamasız
+	app.Get("/metrics", metrics.Handler())
+
 	//API rotalarını gruplayalım
 	apiV1 := app.Group("/api/v1")
+	apiV1.Use(auth.Authenticate(*cfg))
 
 	apiV1.Get("/health", func(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -43,44 +196,157 @@ func main() {
 		})
 	})
 
-	apiV1.Get("/panel/metrics", func(c *fiber.Ctx) error {
-		query := `mppt_values{sensor="panel gucu"}`
+	// Canlılık: süreç istek karşılayabiliyor mu
+	apiV1.Get("/live", func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"status":         "up",
+			"uptime_seconds": int(time.Since(startedAt).Seconds()),
+		})
+	})
+
+	// Hazırlık: bağımlılıklar erişilebilir mi
+	apiV1.Get("/ready", func(c *fiber.Ctx) error {
+		report := checker.Check(c.UserContext())
+		for name, result := range report.Checks {
+			if result.Error != "" {
+				slog.WarnContext(c.UserContext(), "readiness check failed", "check", name, "critical", result.Critical, "error", result.Error)
+			}
+		}
+		status := fiber.StatusOK
+		if report.Status == health.StatusDown {
+			status = fiber.StatusServiceUnavailable
+		}
+		return c.Status(status).JSON(report)
+	})
+
+	// API sözleşmesi ve dokümantasyon arayüzü
+	apiV1.Get("/openapi.json", openapi.SpecHandler)
+	apiV1.Get("/docs", openapi.DocsHandler)
+
+	viewer := auth.Require(auth.RoleViewer)
+	operator := auth.Require(auth.RoleOperator)
+
+	apiV1.Get("/panel/metrics", viewer, func(c *fiber.Ctx) error {
+		query := panelQuery
+
+		// Tenant'a bağlı kimlikler yalnızca kendi etiketlerine ait serileri görür
+		promClient, err := scopedVM(c, vmClient)
+		if promClient == nil {
+			return err
+		}
 
-		result, err := vmClient.Query(query)
+		result, err := promClient.Query(c.UserContext(), query)
 		if err != nil {
-			log.Printf("Error querying VictoriaMetrics: %v", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Failed to query VictoriaMetrics",
-			})
+			slog.ErrorContext(c.UserContext(), "error querying VictoriaMetrics", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to query VictoriaMetrics")
 		}
 
 		return c.Status(fiber.StatusOK).JSON(result)
 	})
 
+	// Son panel okumalarını ve kaydedilen tahminleri SSE ile yayınla
+	apiV1.Get("/stream", viewer, func(c *fiber.Ctx) error {
+		principal := auth.PrincipalFrom(c)
+		scope := stream.Scope{TenantID: principal.TenantID}
+		if principal.Tenant != nil {
+			scope.LabelName = principal.Tenant.LabelName
+			scope.LabelValue = principal.Tenant.LabelValue
+		}
+		return hub.ServeSSE(c, scope)
+	})
+
 	//ML API rotaları
-	forecasterGroup := apiV1.Group("/forecaster")
+	forecasterGroup := apiV1.Group("/forecaster", operator)
+
+	// Eğitim verisinin kalitesini tahminden önce denetle
+	preflight := &preflighter{vm: vmClient, checker: quality.Checker{
+		Mode: cfg.QualityMode,
+		Step: cfg.QualityStep,
+		Thresholds: quality.Thresholds{
+			MinScore: cfg.QualityMinScore,
+			MinGap:   cfg.QualityMinGap,
+			FlatLine: cfg.QualityFlatLine,
+			MaxValue: cfg.QualityMaxValue,
+			OutlierZ: cfg.QualityOutlierZ,
+		},
+	}}
+	preflight.register(forecasterGroup)
+
+	// Tahminin yeniden üretilebilmesi için eğitim verisini sakla
+	snapshots := &snapshotter{vm: vmClient, enabled: cfg.TrainingSnapshots, step: cfg.TrainingSnapshotStep}
+
+	// Tahminler ML servisi, yerleşik baseline, dış dosyalar veya bunların
+	// ağırlıklı birleşimiyle üretilir
+	providers, err := newForecastProviders(cfg, sfClient, vmClient)
+	if err != nil {
+		(&server{sessionSyncer: sessionSyncer, sessionReaper: sessionReaper, sfClient: sfClient}).close()
+		return nil, fmt.Errorf("failed to configure forecast providers: %w", err)
+	}
+	providers.register(forecasterGroup, saveForecast)
+
+	// Çift tıklama ve proxy tekrarlarının yeni tahmin başlatmasını önler
+	idempotent := idempotency.Middleware(cfg.IdempotencyTTL, cfg.IdempotencyLockTimeout)
 
 	//JSON ile anlık tahmin isteği
-	forecasterGroup.Post("/run", func(c *fiber.Ctx) error {
+	forecasterGroup.Post("/run", idempotent, func(c *fiber.Ctx) error {
 		// İstek gövdesini oku
 		var reqPayload client.RunRequest
 		if err := c.BodyParser(&reqPayload); err != nil {
-			return c.Status(400).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Invalid request payload",
-			})
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid request payload")
+		}
+		if errs := validate.Struct(reqPayload); len(errs) > 0 {
+			return apierror.WriteDetails(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid request payload", errs)
+		}
+		// Forecaster'ın dakikalar sonra başarısız olmasındansa adresi şimdi dene.
+		// İzin listesi dışındaki adreslere solar-scope istek göndermez.
+		if cfg.PrometheusCheckTimeout > 0 && client.PrometheusURLAllowed(reqPayload.PrometheusURL, cfg.PrometheusCheckAllowlist) {
+			ctx, cancel := context.WithTimeout(c.UserContext(), cfg.PrometheusCheckTimeout)
+			err := client.CheckPrometheusURL(ctx, reqPayload.PrometheusURL)
+			cancel()
+			if err != nil {
+				slog.WarnContext(c.UserContext(), "PROMETHEUS_URL is not reachable", "url", reqPayload.PrometheusURL, "error", err)
+				return apierror.WriteDetails(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid request payload", []validate.FieldError{
+					{Field: "PROMETHEUS_URL", Message: "is not reachable: " + err.Error()},
+				})
+			}
+		}
+		principal := auth.PrincipalFrom(c)
+		if !principal.IsSystem() {
+			scoped, err := tenant.ScopeSelector(reqPayload.MetricName, principal.Tenant)
+			if err != nil {
+				return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, err.Error())
+			}
+			reqPayload.MetricName = scoped
+		}
+		p, err := providers.choose(c)
+		if p == nil {
+			return err
+		}
+		snapshot, err := snapshots.wanted(c)
+		if err != nil {
+			return err
+		}
+		report := preflight.run(c, reqPayload.MetricName, reqPayload.TrainDays)
+		if blocked, err := preflight.guard(c, report); blocked {
+			return err
+		}
+		var snapshotID *uint
+		if snapshot {
+			snapshotID = snapshots.capture(c, reqPayload.MetricName, reqPayload.TrainDays)
 		}
-		result, err := sfClient.RunForecast(reqPayload)
+		result, err := providers.forecast(c, p, provider.Request{Run: &reqPayload, Params: runRequestParams(reqPayload)})
 		if err != nil {
-			log.Printf("Error calling RunForecast: %v", err)
-			return c.Status(500).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Failed to run forecast",
-			})
+			slog.ErrorContext(c.UserContext(), "error running forecast", "provider", p.Name(), "error", err)
+			return forecastError(c, err, "Failed to run forecast")
+		}
+		if report != nil {
+			result["data_quality"] = report
+		}
+		if snapshotID != nil {
+			result["snapshot_id"] = *snapshotID
 		}
 
-		go database.SaveResultToDB(result)
+		saveForecast(c.UserContext(), result, principal.TenantID)
 
 		return c.Status(200).JSON(result)
 	})
@@ -89,66 +355,143 @@ func main() {
 		// Dosyayı oku
 		file, err := c.FormFile("env_file")
 		if err != nil {
-			return c.Status(400).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Failed to read env file",
-			})
-		}
-
-		// Geçici bir dosyaya kaydet
-		tempPath := fmt.Sprintf("./temp_%s", file.Filename)
-		if err := c.SaveFile(file, tempPath); err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Failed to save env file",
-			})
-		}
-		defer os.Remove(tempPath) // İşlem sonrası dosyayı sil
-		result, err := sfClient.UploadEnvFile(tempPath)
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Failed to read env file")
+		}
+
+		content, err := readFormFile(file)
 		if err != nil {
-			log.Printf("Error calling UploadEnvFile: %v", err)
-			return c.Status(500).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Failed to upload env file",
-			})
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to read env file")
 		}
-		return c.Status(200).JSON(result)
+
+		// Session opsiyonel olarak tenant'ın bir sahasına bağlanabilir
+		principal := auth.PrincipalFrom(c)
+		session := &models.ForecasterSession{TenantID: principal.TenantID}
+		if id := c.FormValue("site_id"); id != "" {
+			site, err := findSessionSite(c, id)
+			if site == nil {
+				return err
+			}
+			session.SiteID = &site.ID
+			session.TenantID = site.TenantID
+		}
+		// Session'a özel ömür; verilmezse tenant'ın veya varsayılan süre geçerlidir
+		if session.TTLSeconds, err = parseSessionTTL(c.FormValue("ttl")); err != nil {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, err.Error())
+		}
+		return uploadSession(c, sfClient, content, session)
+	})
+	// JSON parametrelerle session oluştur; parametreler doğrulanıp env dosyasına çevrilir
+	forecasterGroup.Post("/sessions", func(c *fiber.Ctx) error {
+		var req struct {
+			Params map[string]interface{} `json:"params"`
+			SiteID *uint                  `json:"site_id"`
+			TTL    string                 `json:"ttl"`
+		}
+		if err := c.BodyParser(&req); err != nil || len(req.Params) == 0 {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid session payload, params is required")
+		}
+		values, err := envfile.Stringify(req.Params)
+		if err != nil {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, err.Error())
+		}
+		if problems := envfile.Validate(values); len(problems) > 0 {
+			return apierror.WriteDetails(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid session params", problems)
+		}
+
+		session := &models.ForecasterSession{TenantID: auth.PrincipalFrom(c).TenantID}
+		if req.SiteID != nil {
+			site, err := findSessionSite(c, strconv.FormatUint(uint64(*req.SiteID), 10))
+			if site == nil {
+				return err
+			}
+			session.SiteID = &site.ID
+			session.TenantID = site.TenantID
+		}
+		if session.TTLSeconds, err = parseSessionTTL(req.TTL); err != nil {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, err.Error())
+		}
+		return uploadSession(c, sfClient, envfile.Format(values), session)
 	})
 	// session_id ile tahmin isteği (opsiyonel overrides ile)
-	forecasterGroup.Post("/run-with-env/:session_id", func(c *fiber.Ctx) error {
+	forecasterGroup.Post("/run-with-env/:session_id", idempotent, func(c *fiber.Ctx) error {
 		sessionID := c.Params("session_id")
+		principal := auth.PrincipalFrom(c)
+		if owned, err := database.SessionBelongsTo(principal.TenantID, sessionID); err != nil || !owned {
+			return sessionAccessError(c, err)
+		}
 		var overrides map[string]interface{}
 		// Body boş değilse, overrides'ı ayrıştır
 		if len(c.Body()) > 0 {
 			if err := c.BodyParser(&overrides); err != nil {
-				return c.Status(400).JSON(fiber.Map{
-					"status":  "error",
-					"message": "Invalid overrides payload",
-				})
+				return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid overrides payload")
 			}
 		}
-		result, err := sfClient.RunWithEnv(sessionID, overrides)
+		if !principal.IsSystem() {
+			if overrides == nil {
+				overrides = map[string]interface{}{}
+			}
+			if err := tenant.ScopeOverrides(overrides, principal.Tenant); err != nil {
+				return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, err.Error())
+			}
+		}
+		p, err := providers.choose(c)
+		if p == nil {
+			return err
+		}
+		snapshot, err := snapshots.wanted(c)
+		if err != nil {
+			return err
+		}
+		params := envParams(sessionValues(c, sessionID, overrides))
+		metric, trainDays := params.MetricName, params.TrainDays
+		report := preflight.run(c, metric, trainDays)
+		if blocked, err := preflight.guard(c, report); blocked {
+			return err
+		}
+		var snapshotID *uint
+		if snapshot {
+			snapshotID = snapshots.capture(c, metric, trainDays)
+		}
+		result, err := providers.forecast(c, p, provider.Request{SessionID: sessionID, Overrides: overrides, Params: params})
 		if err != nil {
-			log.Printf("Error calling RunWithEnv: %v", err)
-			return c.Status(500).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Failed to run with env",
-			})
+			slog.ErrorContext(c.UserContext(), "error running forecast with env", "provider", p.Name(), "error", err)
+			return forecastError(c, err, "Failed to run with env")
+		}
+		if report != nil {
+			result["data_quality"] = report
+		}
+		if snapshotID != nil {
+			result["snapshot_id"] = *snapshotID
+		}
+		if err := database.TouchSession(c.UserContext(), sessionID); err != nil {
+			slog.ErrorContext(c.UserContext(), "error updating session last use", "error", err)
 		}
 
-		go database.SaveResultToDB(result)
+		saveForecast(c.UserContext(), result, principal.TenantID)
 
 		return c.Status(200).JSON(result)
 	})
 	// Mevcut session'ları listele
 	forecasterGroup.Get("/sessions", func(c *fiber.Ctx) error {
-		result, err := sfClient.GetSessions()
+		result, err := sfClient.GetSessions(c.UserContext())
 		if err != nil {
-			log.Printf("Error calling GetSessions: %v", err)
-			return c.Status(500).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Failed to get sessions",
-			})
+			slog.ErrorContext(c.UserContext(), "error calling GetSessions", "error", err)
+			return apierror.Upstream(c, err, "Failed to get sessions")
+		}
+
+		// Tenant'a bağlı kimlikler yalnızca kendi session'larını görür
+		principal := auth.PrincipalFrom(c)
+		if !principal.IsSystem() {
+			owned, err := database.ListSessionIDs(principal.TenantID)
+			if err != nil {
+				slog.ErrorContext(c.UserContext(), "error listing tenant sessions", "error", err)
+				return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to get sessions")
+			}
+			allowed := make(map[string]bool, len(owned))
+			for _, id := range owned {
+				allowed[id] = true
+			}
+			result = client.FilterSessions(result, func(id string) bool { return allowed[id] })
 		}
 		return c.Status(200).JSON(result)
 	})
@@ -156,40 +499,37 @@ func main() {
 	// session sil
 	forecasterGroup.Delete("/sessions/:session_id", func(c *fiber.Ctx) error {
 		sessionID := c.Params("session_id")
-		result, err := sfClient.DeleteSession(sessionID)
+		if owned, err := database.SessionBelongsTo(auth.PrincipalFrom(c).TenantID, sessionID); err != nil || !owned {
+			return sessionAccessError(c, err)
+		}
+		result, err := sfClient.DeleteSession(c.UserContext(), sessionID)
 		if err != nil {
-			log.Printf("Error calling DeleteSession: %v", err)
-			return c.Status(500).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Failed to delete session",
-			})
+			slog.ErrorContext(c.UserContext(), "error calling DeleteSession", "error", err)
+			return apierror.Upstream(c, err, "Failed to delete session")
+		}
+		if err := database.DeleteSessionRecord(sessionID); err != nil {
+			slog.ErrorContext(c.UserContext(), "error deleting session owner record", "error", err)
 		}
 		return c.Status(200).JSON(result)
 	})
 
 	// Örnek .env dosyasını al
 	forecasterGroup.Get("/sample-env", func(c *fiber.Ctx) error {
-		result, err := sfClient.GetSampleEnv()
+		result, err := sfClient.GetSampleEnv(c.UserContext())
 		if err != nil {
-			log.Printf("Error calling GetSampleEnv: %v", err)
-			return c.Status(500).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Failed to get sample env",
-			})
+			slog.ErrorContext(c.UserContext(), "error calling GetSampleEnv", "error", err)
+			return apierror.Upstream(c, err, "Failed to get sample env")
 		}
 		return c.Status(200).JSON(result)
 	})
 
-	forecastsGroup := apiV1.Group("/forecasts")
+	forecastsGroup := apiV1.Group("/forecasts", viewer)
 	// Depolanan tahminleri listele
 	forecastsGroup.Get("/", func(c *fiber.Ctx) error {
-		forecasts, err := database.GetRecentForecasts(10) // Son 10 tahmini al
+		forecasts, err := database.GetRecentForecasts(c.UserContext(), auth.PrincipalFrom(c).TenantID, 10) // Son 10 tahmini al
 		if err != nil {
-			log.Printf("Error retrieving forecasts: %v", err)
-			return c.Status(500).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Failed to retrieve forecasts",
-			})
+			slog.ErrorContext(c.UserContext(), "error retrieving forecasts", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve forecasts")
 		}
 		return c.Status(200).JSON(forecasts)
 	})
@@ -197,27 +537,45 @@ func main() {
 	// Belirli bir tahmini ID ile al
 	forecastsGroup.Get("/:id", func(c *fiber.Ctx) error {
 		id := c.Params("id")
-		forecast, err := database.GetForecastByID(id)
+		forecast, err := database.GetForecastByID(c.UserContext(), auth.PrincipalFrom(c).TenantID, id)
 		if err != nil {
-			log.Printf("Error retrieving forecast by ID: %v", err)
-			return c.Status(500).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Failed to retrieve forecast",
-			})
+			slog.ErrorContext(c.UserContext(), "error retrieving forecast by ID", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve forecast")
 		}
 		if forecast == nil {
-			return c.Status(404).JSON(fiber.Map{
-				"status":  "error",
-				"message": "Forecast not found",
-			})
+			return apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "Forecast not found")
 		}
 		return c.Status(200).JSON(forecast)
 	})
 
-	log.Printf("Starting server on port %s", cfg.AppPort)
+	registerAdminRoutes(apiV1)
+	registerSiteRoutes(apiV1, viewer, operator, &sitePV{vm: vmClient, minRatio: cfg.PerformanceRatioMin})
+	registerSessionRoutes(apiV1, viewer, sessionSyncer, sessionReaper)
+	registerEnvTemplateRoutes(apiV1, viewer, operator, sfClient)
+	registerSnapshotRoutes(apiV1, viewer)
+	registerSolarRoutes(apiV1, viewer)
 
-	err = app.Listen("0.0.0.0:" + cfg.AppPort)
+	return &server{
+		app:           app,
+		hub:           hub,
+		sessionSyncer: sessionSyncer,
+		sessionReaper: sessionReaper,
+		sfClient:      sfClient,
+	}, nil
+}
+
+// sessionAccessError, session sahiplik kontrolü başarısız olduğunda yanıt döner.
+// Session başka bir tenant'a aitse varlığını sızdırmamak için 404 döner.
+func sessionAccessError(c *fiber.Ctx, err error) error {
 	if err != nil {
-		log.Fatalf("Error starting server: %v", err)
+		slog.ErrorContext(c.UserContext(), "error checking session owner", "error", err)
+		return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to check session owner")
 	}
+	return apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "Session not found")
+}
+
+// fatal, hatayı loglar ve süreci sonlandırır.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
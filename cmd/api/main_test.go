@@ -0,0 +1,31 @@
+package main
+
+import (
+	"solar-scope/internal/config"
+	"solar-scope/internal/openapi"
+	"testing"
+)
+
+// TestRoutesDocumented, uygulamadaki her rotanın OpenAPI spec'inde
+// tanımlandığını doğrular.
+func TestRoutesDocumented(t *testing.T) {
+	cfg := config.LoadConfig()
+	// Arka plan döngüleri veritabanına ve forecaster'a erişmesin
+	cfg.SessionSyncInterval = 0
+	cfg.SessionReapInterval = 0
+	cfg.ForecasterHealthInterval = 0
+
+	srv, err := newServer(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.close()
+
+	missing, err := openapi.Verify(srv.app.GetRoutes(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) > 0 {
+		t.Errorf("routes missing from OpenAPI spec: %v", missing)
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"solar-scope/internal/apierror"
+	"solar-scope/internal/auth"
+	"solar-scope/internal/baseline"
+	"solar-scope/internal/client"
+	"solar-scope/internal/config"
+	"solar-scope/internal/provider"
+	"solar-scope/internal/tenant"
+	"solar-scope/internal/validate"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// panelQuery, panel gücü serisidir; METRIC_NAME verilmeyen baseline
+// isteklerinde kullanılır.
+const panelQuery = `mppt_values{sensor="panel gucu"}`
+
+// forecastProviders, tahmin isteklerini seçilen sağlayıcıya yönlendirir.
+type forecastProviders struct {
+	registry    provider.Registry
+	defaultName string
+	weights     map[string]float64
+	vm          *client.PrometheusClient
+	// fallback, ML forecaster'ın devresi açıkken kullanılır; nil ise kapalıdır
+	fallback provider.Provider
+}
+
+// newForecastProviders, yapılandırmadaki sağlayıcıları kurar. file sağlayıcısı
+// yalnızca FORECAST_FILE_DIR verilmişse eklenir.
+func newForecastProviders(cfg *config.Config, sfClient *client.SolarForecasterClient, vm *client.PrometheusClient) (*forecastProviders, error) {
+	forecaster := baseline.Forecaster{Step: cfg.BaselineStep}
+	registry := provider.Registry{
+		provider.NameML:       provider.ML{Client: sfClient},
+		provider.NameBaseline: provider.Baseline{Forecaster: forecaster},
+	}
+	if cfg.ForecastFileDir != "" {
+		registry[provider.NameFile] = provider.File{Dir: cfg.ForecastFileDir, MaxAge: cfg.ForecastFileMaxAge}
+	}
+	weights, err := provider.ParseWeights(cfg.EnsembleWeights)
+	if err != nil {
+		return nil, err
+	}
+	ensemble, err := provider.NewEnsemble(registry, weights)
+	if err != nil {
+		return nil, err
+	}
+	registry[provider.NameEnsemble] = ensemble
+	if !registry.Has(cfg.ForecastProvider) {
+		return nil, fmt.Errorf("unknown FORECAST_PROVIDER %q, available: %v", cfg.ForecastProvider, registry.Names())
+	}
+
+	providers := &forecastProviders{registry: registry, defaultName: cfg.ForecastProvider, weights: weights, vm: vm}
+	if cfg.BaselineFallback {
+		providers.fallback = provider.Baseline{Forecaster: forecaster, Reason: baseline.ReasonCircuitOpen}
+	}
+	return providers, nil
+}
+
+// register, sağlayıcı listesi ve baseline tahmin rotalarını ekler.
+func (f *forecastProviders) register(forecasterGroup fiber.Router, saveForecast func(context.Context, map[string]interface{}, uint)) {
+	forecasterGroup.Get("/providers", func(c *fiber.Ctx) error {
+		return c.Status(200).JSON(fiber.Map{
+			"default":           f.defaultName,
+			"providers":         f.registry.Names(),
+			"ensemble_weights":  f.weights,
+			"baseline_fallback": f.fallback != nil,
+		})
+	})
+
+	forecasterGroup.Post("/baseline", func(c *fiber.Ctx) error {
+		params := baseline.Params{MetricName: panelQuery, BatteryParams: client.BatteryParams{TrainDays: 7}}
+		if err := c.BodyParser(&params); err != nil {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid request payload")
+		}
+		if errs := validate.Struct(withMethod(params)); len(errs) > 0 {
+			return apierror.WriteDetails(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid request payload", errs)
+		}
+		principal := auth.PrincipalFrom(c)
+		if !principal.IsSystem() {
+			scoped, err := tenant.ScopeSelector(params.MetricName, principal.Tenant)
+			if err != nil {
+				return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, err.Error())
+			}
+			params.MetricName = scoped
+		}
+		result, err := provider.Forecast(c.UserContext(), f.registry[provider.NameBaseline], f.request(c, provider.Request{Params: params}))
+		if errors.Is(err, baseline.ErrNoHistory) {
+			return apierror.Write(c, fiber.StatusUnprocessableEntity, apierror.CodeDataQuality, "No production history in the training window")
+		}
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "error running baseline forecast", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to query VictoriaMetrics")
+		}
+
+		saveForecast(c.UserContext(), result, principal.TenantID)
+
+		return c.Status(200).JSON(result)
+	})
+}
+
+// choose, ?provider ile istenen sağlayıcıyı, verilmemişse varsayılanı döner.
+// Bilinmeyen bir ad için hata yanıtını yazar ve nil döner.
+func (f *forecastProviders) choose(c *fiber.Ctx) (provider.Provider, error) {
+	name := c.Query("provider", f.defaultName)
+	p, err := f.registry.Get(name)
+	if err != nil {
+		return nil, apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, err.Error())
+	}
+	return p, nil
+}
+
+// request, isteği çağıranın tenant'ıyla tamamlar.
+func (f *forecastProviders) request(c *fiber.Ctx, req provider.Request) provider.Request {
+	req.TenantID = auth.PrincipalFrom(c).TenantID
+	if pc, err := tenantVM(c, f.vm); err == nil {
+		req.VM = pc
+	}
+	return req
+}
+
+// forecast, sağlayıcıyı çalıştırır. Forecaster çağrısı devre kesici açık
+// olduğu için reddedildiyse ve yedek açıksa baseline tahmini döner; baseline
+// da üretilemezse özgün hata döner.
+func (f *forecastProviders) forecast(c *fiber.Ctx, p provider.Provider, req provider.Request) (map[string]interface{}, error) {
+	req = f.request(c, req)
+	result, err := provider.Forecast(c.UserContext(), p, req)
+	if err == nil || f.fallback == nil || !errors.Is(err, client.ErrCircuitOpen) {
+		return result, err
+	}
+	fallback, fallbackErr := provider.Forecast(c.UserContext(), f.fallback, req)
+	if fallbackErr != nil {
+		slog.ErrorContext(c.UserContext(), "baseline fallback failed", "error", fallbackErr)
+		return nil, err
+	}
+	slog.WarnContext(c.UserContext(), "forecaster circuit is open, served a baseline forecast", "provider", p.Name(), "session_id", fallback["session_id"])
+	return fallback, nil
+}
+
+// forecastError, sağlayıcının hatasını yanıta çevirir. Dosya sağlayıcısının
+// geçersiz dosyası çağıranın değil dosyayı bırakan sistemin hatası olduğundan
+// sorunu açıklayan bir 502 döner; diğer hatalar apierror.Upstream'e kalır.
+func forecastError(c *fiber.Ctx, err error, fallback string) error {
+	if errors.Is(err, provider.ErrInvalidFile) {
+		return apierror.Write(c, fiber.StatusBadGateway, apierror.CodeUpstreamError, err.Error())
+	}
+	return apierror.Upstream(c, err, fallback)
+}
+
+// withMethod, boş yöntemi doğrulama için geçerli bir değerle doldurur; boş
+// yöntem geçmişe göre seçilir.
+func withMethod(p baseline.Params) baseline.Params {
+	if p.Method == "" {
+		p.Method = baseline.MethodClearSky
+	}
+	return p
+}
+
+// runRequestParams, /run gövdesini baseline parametrelerine çevirir.
+func runRequestParams(req client.RunRequest) baseline.Params {
+	return baseline.Params{MetricName: req.MetricName, BatteryParams: req.BatteryParams}
+}
+
+// envParams, session'ın env değerlerini baseline parametrelerine çevirir.
+// Geçersiz sayılar 0 olarak kalır; TRAIN_DAYS bilinmiyorsa 7 gün kullanılır.
+func envParams(values map[string]string) baseline.Params {
+	number := func(key string) float64 {
+		v, _ := strconv.ParseFloat(values[key], 64)
+		return v
+	}
+	p := baseline.Params{
+		MetricName: values["METRIC_NAME"],
+		BatteryParams: client.BatteryParams{
+			TrainDays:           int(number("TRAIN_DAYS")),
+			BatteryCapacityWh:   number("BATTERY_CAPACITY_WH"),
+			InitialSocPercent:   number("INITIAL_SOC_PERCENT"),
+			ConstantLoadW:       number("CONSTANT_LOAD_W"),
+			ChargeEfficiency:    number("CHARGE_EFFICIENCY"),
+			DischargeEfficiency: number("DISCHARGE_EFFICIENCY"),
+		},
+	}
+	if p.TrainDays <= 0 {
+		p.TrainDays = 7
+	}
+	return p
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"solar-scope/internal/apierror"
+	"solar-scope/internal/provider"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestForecastError(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		code    apierror.Code
+		message string
+	}{
+		{"invalid file", fmt.Errorf("%w f.json: timestamp must be a string", provider.ErrInvalidFile), fiber.StatusBadGateway, apierror.CodeUpstreamError, "f.json: timestamp must be a string"},
+		{"other error", errors.New("boom"), fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to run forecast"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error { return forecastError(c, tt.err, "Failed to run forecast") })
+			resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var body apierror.Response
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.status || body.Code != tt.code || !strings.Contains(body.Message, tt.message) {
+				t.Errorf("forecastError() = %d %+v, want %d %s %q", resp.StatusCode, body, tt.status, tt.code, tt.message)
+			}
+		})
+	}
+}
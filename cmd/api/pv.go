@@ -0,0 +1,269 @@
+package main
+
+import (
+	"log/slog"
+	"solar-scope/database"
+	"solar-scope/internal/apierror"
+	"solar-scope/internal/auth"
+	"solar-scope/internal/client"
+	"solar-scope/internal/pv"
+	"solar-scope/internal/solar"
+	"solar-scope/internal/validate"
+	"solar-scope/models"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/common/model"
+)
+
+// Performans penceresinin sınırları ve varsayılan ortam sıcaklığı.
+const (
+	maxPerformanceWindow = 7 * 24 * time.Hour
+	maxPerformancePoints = 10000
+	defaultAmbientC      = 20
+)
+
+// Işınım kaynakları.
+const (
+	irradianceClearSky = "clear_sky"
+	irradianceMeasured = "measured"
+	irradianceSupplied = "supplied"
+)
+
+// sitePV, sahaların panel dizisini ve fiziksel modele göre beklenen
+// gücünü sunar.
+type sitePV struct {
+	vm       *client.PrometheusClient
+	minRatio float64
+}
+
+// register, panel dizisi ve beklenen güç rotalarını saha grubuna ekler.
+func (s *sitePV) register(sitesGroup fiber.Router, operator fiber.Handler) {
+	sitesGroup.Put("/:id/pv", operator, func(c *fiber.Ctx) error {
+		var system models.PVSystem
+		if err := c.BodyParser(&system); err != nil {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid PV system payload")
+		}
+		if errs := validate.Struct(system); len(errs) > 0 {
+			return apierror.WriteDetails(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid PV system payload", errs)
+		}
+		site, err := database.SetSitePV(auth.PrincipalFrom(c).TenantID, c.Params("id"), system)
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "error updating site PV system", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to update site")
+		}
+		if site == nil {
+			return apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "Site not found")
+		}
+		return c.Status(200).JSON(site)
+	})
+
+	// Bir andaki beklenen güç. ghi verilirse (dni ve dhi verilmezse Erbs
+	// ayrıştırmasıyla) o ışınım, verilmezse açık gök ışınımı kullanılır.
+	sitesGroup.Get("/:id/expected", func(c *fiber.Ctx) error {
+		site, err := s.site(c)
+		if site == nil {
+			return err
+		}
+		when := time.Now()
+		if value := c.Query("time"); value != "" {
+			if when, err = time.Parse(time.RFC3339, value); err != nil {
+				return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "time must be an RFC 3339 timestamp")
+			}
+		}
+		ambient, err := strconv.ParseFloat(c.Query("temp_c", strconv.Itoa(defaultAmbientC)), 64)
+		if err != nil {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "temp_c must be a number in °C")
+		}
+
+		pos := solar.SunPosition(*site.PV.Latitude, *site.PV.Longitude, when)
+		irradiance, source := solar.ClearSky(pos, site.PV.AltitudeM), irradianceClearSky
+		if c.Query("ghi") != "" || c.Query("dni") != "" || c.Query("dhi") != "" {
+			supplied, err := suppliedIrradiance(c, pos)
+			if supplied == nil {
+				return err
+			}
+			irradiance, source = *supplied, irradianceSupplied
+		}
+		return c.Status(200).JSON(fiber.Map{
+			"site_id":           site.ID,
+			"time":              when,
+			"position":          pos,
+			"irradiance":        irradiance,
+			"irradiance_source": source,
+			"ambient_temp_c":    ambient,
+			"expected":          pv.Expected(pvSystem(site.PV), pos, irradiance, ambient),
+		})
+	})
+
+	// Ölçülen üretimin fiziksel beklentiye oranı. Ölçülen ışınım metriği
+	// yoksa beklenti açık gök içindir; bulutlu saatlerde oran arıza olmadan
+	// da düşer.
+	sitesGroup.Get("/:id/performance", func(c *fiber.Ctx) error {
+		site, err := s.site(c)
+		if site == nil {
+			return err
+		}
+		window, windowErr := time.ParseDuration(c.Query("window", "24h"))
+		step, stepErr := time.ParseDuration(c.Query("step", "5m"))
+		if windowErr != nil || stepErr != nil || window <= 0 || window > maxPerformanceWindow || step < time.Minute {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "window must be a duration up to 168h and step at least 1m")
+		}
+		if window/step > maxPerformancePoints {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "window has too many steps, increase step")
+		}
+		ambient, err := strconv.ParseFloat(c.Query("temp_c", strconv.Itoa(defaultAmbientC)), 64)
+		if err != nil {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "temp_c must be a number in °C")
+		}
+		pc, err := scopedVM(c, s.vm)
+		if pc == nil {
+			return err
+		}
+
+		end := time.Now().Truncate(step)
+		start := end.Add(-window)
+		metric := site.PV.ProductionMetric
+		if metric == "" {
+			metric = panelQuery
+		}
+		production, err := pc.QueryRange(c.UserContext(), metric, start, end, step)
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "error querying site production", "site_id", site.ID, "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to query VictoriaMetrics")
+		}
+		actual := byTimestamp(production, false)
+
+		source := irradianceClearSky
+		var measured map[int64]float64
+		if site.PV.IrradianceMetric != "" {
+			matrix, err := pc.QueryRange(c.UserContext(), site.PV.IrradianceMetric, start, end, step)
+			if err != nil {
+				slog.ErrorContext(c.UserContext(), "error querying site irradiance", "site_id", site.ID, "error", err)
+				return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to query VictoriaMetrics")
+			}
+			source, measured = irradianceMeasured, byTimestamp(matrix, true)
+		}
+
+		system := pvSystem(site.PV)
+		var points []pv.Point
+		for at := start; !at.After(end); at = at.Add(step) {
+			pos := solar.SunPosition(*site.PV.Latitude, *site.PV.Longitude, at)
+			irradiance := solar.ClearSky(pos, site.PV.AltitudeM)
+			if measured != nil {
+				// Işınım ölçümü olmayan adımın beklentisi bilinmez
+				ghi, ok := measured[at.Unix()]
+				if !ok {
+					continue
+				}
+				irradiance = pv.FromGHI(pos, ghi)
+			}
+			estimate := pv.Expected(system, pos, irradiance, ambient)
+			point := pv.Point{Time: at, ExpectedW: estimate.ACW, POA: estimate.POA}
+			if value, ok := actual[at.Unix()]; ok {
+				point.ActualW = &value
+			}
+			points = append(points, point)
+		}
+		perf := pv.Summarize(points, step)
+
+		status := "ok"
+		switch {
+		case perf.Coverage == 0:
+			status = "no_data"
+		case perf.Ratio == nil:
+			status = "no_daylight"
+		case *perf.Ratio < s.minRatio:
+			status = "underperforming"
+		}
+		return c.Status(200).JSON(fiber.Map{
+			"site_id":           site.ID,
+			"start":             start,
+			"end":               end,
+			"step_seconds":      int64(step / time.Second),
+			"irradiance_source": source,
+			"ambient_temp_c":    ambient,
+			"min_ratio":         s.minRatio,
+			"status":            status,
+			"performance":       perf,
+		})
+	})
+}
+
+// site, istekteki sahayı getirir. Saha yoksa veya panel dizisi
+// tanımlanmamışsa hata yanıtını yazar ve nil döner.
+func (s *sitePV) site(c *fiber.Ctx) (*models.Site, error) {
+	site, err := database.GetSite(auth.PrincipalFrom(c).TenantID, c.Params("id"))
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "error retrieving site", "error", err)
+		return nil, apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve site")
+	}
+	if site == nil {
+		return nil, apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "Site not found")
+	}
+	if !site.PV.Configured() {
+		return nil, apierror.Write(c, fiber.StatusConflict, apierror.CodeConflict, "Site has no PV system, set latitude, longitude and capacity_kwp first")
+	}
+	return site, nil
+}
+
+// pvSystem, sahanın panel dizisini modele çevirir. Azimut verilmemişse
+// panel ekvatora bakar.
+func pvSystem(p models.PVSystem) pv.System {
+	azimuth := 180.0
+	if *p.Latitude < 0 {
+		azimuth = 0
+	}
+	if p.AzimuthDeg != nil {
+		azimuth = *p.AzimuthDeg
+	}
+	return pv.System{
+		CapacityKWp:        p.CapacityKWp,
+		Tilt:               p.TiltDeg,
+		Azimuth:            azimuth,
+		TempCoefficient:    p.TempCoefficient,
+		SystemLosses:       p.SystemLosses,
+		InverterEfficiency: p.InverterEfficiency,
+		DCACRatio:          p.DCACRatio,
+	}
+}
+
+// suppliedIrradiance, sorgudaki ghi, dni ve dhi değerlerini okur. Yalnızca
+// ghi verilmişse bileşenler ondan türetilir. Geçersizse hata yanıtını yazar
+// ve nil döner.
+func suppliedIrradiance(c *fiber.Ctx, pos solar.Position) (*solar.Irradiance, error) {
+	ghi, err := strconv.ParseFloat(c.Query("ghi"), 64)
+	if err != nil || ghi < 0 {
+		return nil, apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "ghi must be a non-negative number in W/m²")
+	}
+	if c.Query("dni") == "" && c.Query("dhi") == "" {
+		irradiance := pv.FromGHI(pos, ghi)
+		return &irradiance, nil
+	}
+	dni, dniErr := strconv.ParseFloat(c.Query("dni"), 64)
+	dhi, dhiErr := strconv.ParseFloat(c.Query("dhi"), 64)
+	if dniErr != nil || dhiErr != nil || dni < 0 || dhi < 0 {
+		return nil, apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "dni and dhi must be given together as non-negative numbers in W/m²")
+	}
+	return &solar.Irradiance{GHI: ghi, DNI: dni, DHI: dhi}, nil
+}
+
+// byTimestamp, serileri zaman damgasına göre toplar; mean ise ortalamasını
+// alır.
+func byTimestamp(matrix model.Matrix, mean bool) map[int64]float64 {
+	sums := map[int64]float64{}
+	counts := map[int64]int{}
+	for _, stream := range matrix {
+		for _, sample := range stream.Values {
+			sums[sample.Timestamp.Unix()] += float64(sample.Value)
+			counts[sample.Timestamp.Unix()]++
+		}
+	}
+	if mean {
+		for ts, n := range counts {
+			sums[ts] /= float64(n)
+		}
+	}
+	return sums
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"log/slog"
+	"solar-scope/internal/apierror"
+	"solar-scope/internal/auth"
+	"solar-scope/internal/client"
+	"solar-scope/internal/quality"
+	"solar-scope/internal/tenant"
+	"solar-scope/internal/validate"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// preflightRequest, veri kalitesi ön kontrolünün isteğidir; /run gövdesinin
+// ilgili alanlarıyla aynıdır.
+type preflightRequest struct {
+	MetricName string `json:"METRIC_NAME" validate:"required,promql"`
+	TrainDays  int    `json:"TRAIN_DAYS" validate:"min=1,max=365"`
+}
+
+// preflighter, tahminlerden önce eğitim verisinin kalitesini denetler.
+type preflighter struct {
+	vm      *client.PrometheusClient
+	checker quality.Checker
+}
+
+// register, isteğe bağlı ön kontrol rotasını ekler. Kontrol QUALITY_MODE
+// kapalı olsa da çalışır.
+func (p *preflighter) register(forecasterGroup fiber.Router) {
+	forecasterGroup.Post("/preflight", func(c *fiber.Ctx) error {
+		var req preflightRequest
+		if err := c.BodyParser(&req); err != nil {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid request payload")
+		}
+		if errs := validate.Struct(req); len(errs) > 0 {
+			return apierror.WriteDetails(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid request payload", errs)
+		}
+		principal := auth.PrincipalFrom(c)
+		if !principal.IsSystem() {
+			scoped, err := tenant.ScopeSelector(req.MetricName, principal.Tenant)
+			if err != nil {
+				return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, err.Error())
+			}
+			req.MetricName = scoped
+		}
+		pc, err := scopedVM(c, p.vm)
+		if pc == nil {
+			return err
+		}
+		report, err := p.checker.Run(c.UserContext(), pc, req.MetricName, req.TrainDays)
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "error checking training data quality", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to query VictoriaMetrics")
+		}
+		return c.Status(200).JSON(report)
+	})
+}
+
+// run, tahmin öncesi kontrolü çalıştırır. Kontrol kapalıysa, parametreler
+// eksikse veya VictoriaMetrics'e ulaşılamazsa nil döner; bu durumlarda tahmin
+// engellenmez.
+func (p *preflighter) run(c *fiber.Ctx, metric string, trainDays int) *quality.Report {
+	if !p.checker.Enabled() || metric == "" || trainDays <= 0 {
+		return nil
+	}
+	pc, err := tenantVM(c, p.vm)
+	if err != nil {
+		slog.WarnContext(c.UserContext(), "skipping training data check", "error", err)
+		return nil
+	}
+	report, err := p.checker.Run(c.UserContext(), pc, metric, trainDays)
+	if err != nil {
+		slog.WarnContext(c.UserContext(), "skipping training data check", "error", err)
+		return nil
+	}
+	if !report.Passed {
+		slog.WarnContext(c.UserContext(), "training data below quality threshold",
+			"metric", metric, "score", report.Score, "min_score", report.MinScore, "issues", report.Issues)
+	}
+	return &report
+}
+
+// guard, rapor tahmini engelliyorsa 422 yanıtını yazar ve true döner.
+func (p *preflighter) guard(c *fiber.Ctx, report *quality.Report) (bool, error) {
+	if report == nil || !p.checker.Blocks(*report) {
+		return false, nil
+	}
+	return true, apierror.WriteDetails(c, fiber.StatusUnprocessableEntity, apierror.CodeDataQuality,
+		"Training data quality is below the configured threshold", report)
+}
+
+// tenantVM, çağıranın yalnızca kendi tenant'ının serilerini görebildiği
+// VictoriaMetrics istemcisini döner.
+func tenantVM(c *fiber.Ctx, vm *client.PrometheusClient) (*client.PrometheusClient, error) {
+	if principal := auth.PrincipalFrom(c); !principal.IsSystem() {
+		return vm.ForTenant(principal.Tenant.LabelName, principal.Tenant.LabelValue)
+	}
+	return vm, nil
+}
+
+// scopedVM, tenantVM gibidir ancak hata durumunda yanıtı yazar.
+func scopedVM(c *fiber.Ctx, vm *client.PrometheusClient) (*client.PrometheusClient, error) {
+	pc, err := tenantVM(c, vm)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "error scoping VictoriaMetrics client", "error", err)
+		return nil, apierror.Write(c, fiber.StatusForbidden, apierror.CodeForbidden, "Tenant has no metric label configured")
+	}
+	return pc, nil
+}
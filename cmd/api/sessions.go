@@ -0,0 +1,215 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"os"
+	"slices"
+	"solar-scope/database"
+	"solar-scope/internal/apierror"
+	"solar-scope/internal/auth"
+	"solar-scope/internal/client"
+	"solar-scope/internal/envfile"
+	"solar-scope/internal/sessions"
+	"solar-scope/internal/tenant"
+	"solar-scope/models"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/datatypes"
+)
+
+// sessionStates, ?state= filtresinde kabul edilen senkronizasyon durumlarıdır.
+var sessionStates = []string{models.SessionSynced, models.SessionLocalOnly, models.SessionUpstreamOnly}
+
+// registerSessionRoutes, yerel session kayıtlarının rotalarını ekler.
+func registerSessionRoutes(apiV1 fiber.Router, viewer fiber.Handler, syncer *sessions.Syncer, reaper *sessions.Reaper) {
+	sessionsGroup := apiV1.Group("/sessions", viewer)
+
+	sessionsGroup.Get("/", func(c *fiber.Ctx) error {
+		state := c.Query("state")
+		if state != "" && !slices.Contains(sessionStates, state) {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "state must be one of synced, local_only, upstream_only")
+		}
+		list, err := database.ListSessions(c.UserContext(), auth.PrincipalFrom(c).TenantID, state, c.Query("site_id"))
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "error listing session records", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to list sessions")
+		}
+		return c.Status(200).JSON(list)
+	})
+
+	// Senkronizasyonu beklemeden çalıştır; sahipsiz session'lar tüm tenant'ları
+	// ilgilendirdiği için yalnızca sistem yöneticilerine açıktır
+	sessionsGroup.Post("/sync", auth.RequireSystem(), func(c *fiber.Ctx) error {
+		report, err := syncer.Sync(c.UserContext())
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "error syncing sessions", "error", err)
+			return apierror.Upstream(c, err, "Failed to sync sessions")
+		}
+		return c.Status(200).JSON(report)
+	})
+
+	// Süresi dolan session'ları beklemeden sil; ?dry_run verilmezse arka plandaki
+	// temizliğin modu kullanılır
+	sessionsGroup.Post("/reap", auth.RequireSystem(), func(c *fiber.Ctx) error {
+		dryRun := reaper.DryRun()
+		if value := c.Query("dry_run"); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "dry_run must be a boolean")
+			}
+			dryRun = parsed
+		}
+		report, err := reaper.Reap(c.UserContext(), dryRun)
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "error reaping sessions", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to reap sessions")
+		}
+		return c.Status(200).JSON(report)
+	})
+
+	// Session'ın parametrelerini POST /forecaster/sessions'ın kabul ettiği
+	// biçimde döner; gizli değerler maskelenmiş olarak saklandığı için *** gelir
+	sessionsGroup.Get("/:session_id/params", func(c *fiber.Ctx) error {
+		session, err := database.GetSession(c.UserContext(), auth.PrincipalFrom(c).TenantID, c.Params("session_id"))
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "error retrieving session record", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve session")
+		}
+		if session == nil {
+			return apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "Session not found")
+		}
+		values, err := envfile.Stringify(session.Params)
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "error reading session params", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to read session params")
+		}
+		return c.Status(200).JSON(fiber.Map{
+			"session_id": session.SessionID,
+			"site_id":    session.SiteID,
+			"params":     envfile.Typed(values),
+		})
+	})
+
+	sessionsGroup.Get("/:session_id", func(c *fiber.Ctx) error {
+		session, err := database.GetSession(c.UserContext(), auth.PrincipalFrom(c).TenantID, c.Params("session_id"))
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "error retrieving session record", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve session")
+		}
+		if session == nil {
+			return apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "Session not found")
+		}
+		return c.Status(200).JSON(session)
+	})
+}
+
+// uploadSession, env içeriğini forecaster'a yükler, oluşan session'ı yerel
+// kayda ekler ve forecaster'ın yanıtını döner. session'ın tenant, saha, TTL ve
+// şablon alanlarını çağıran doldurur. Tenant'a bağlı kimliklerin METRIC_NAME'i
+// yüklemeden önce tenant'a göre sınırlanır.
+func uploadSession(c *fiber.Ctx, sfClient *client.SolarForecasterClient, content []byte, session *models.ForecasterSession) error {
+	tempFile, err := os.CreateTemp("", "solar-scope-*.env")
+	if err != nil {
+		return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to save env file")
+	}
+	tempPath := tempFile.Name()
+	defer os.Remove(tempPath) // İşlem sonrası dosyayı sil
+	_, err = tempFile.Write(content)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to save env file")
+	}
+
+	principal := auth.PrincipalFrom(c)
+	if !principal.IsSystem() {
+		if err := tenant.ScopeEnvFile(tempPath, principal.Tenant); err != nil {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, err.Error())
+		}
+		if content, err = os.ReadFile(tempPath); err != nil {
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to read env file")
+		}
+	}
+
+	result, err := sfClient.UploadEnvFile(c.UserContext(), tempPath)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "error calling UploadEnvFile", "error", err)
+		return apierror.Upstream(c, err, "Failed to upload env file")
+	}
+
+	// Session'ı yükleyen kimliğe bağla; parametrelerin gizli değerleri maskelenir
+	if sessionID, ok := result["session_id"].(string); ok && sessionID != "" {
+		params := datatypes.JSONMap{}
+		for key, value := range envfile.Redact(envfile.Parse(content)) {
+			params[key] = value
+		}
+		session.SessionID = sessionID
+		session.Owner = principal.Subject
+		session.Backend = sfClient.SessionBackend(sessionID)
+		session.Params = params
+		if err := database.RecordSession(c.UserContext(), session); err != nil {
+			slog.ErrorContext(c.UserContext(), "error recording session", "error", err)
+		}
+	}
+	return c.Status(200).JSON(result)
+}
+
+// findSessionSite, session'ın bağlanacağı sahayı çağıranın tenant'ında arar.
+// Saha bulunamazsa nil ve yazılmış hata yanıtını döner.
+func findSessionSite(c *fiber.Ctx, id string) (*models.Site, error) {
+	site, err := database.GetSite(auth.PrincipalFrom(c).TenantID, id)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "error retrieving site", "error", err)
+		return nil, apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve site")
+	}
+	if site == nil {
+		return nil, apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "Site not found")
+	}
+	return site, nil
+}
+
+// parseSessionTTL, session'a özel ömrü saniye olarak döner; boş değer 0'dır.
+func parseSessionTTL(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl < time.Second {
+		return 0, errors.New("ttl must be a duration of at least 1s, e.g. 72h")
+	}
+	return int64(ttl / time.Second), nil
+}
+
+// readFormFile, yüklenen dosyanın içeriğini okur.
+func readFormFile(file *multipart.FileHeader) ([]byte, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// sessionValues, run-with-env çağrısının env değerlerini session'ın kayıtlı
+// parametrelerinden ve üzerine yazılan override'lardan toplar. Kayıtlı
+// parametrelerde gizli değerler maskelidir.
+func sessionValues(c *fiber.Ctx, sessionID string, overrides map[string]interface{}) map[string]string {
+	values := map[string]string{}
+	if session, err := database.GetSession(c.UserContext(), auth.PrincipalFrom(c).TenantID, sessionID); err == nil && session != nil {
+		if params, err := envfile.Stringify(session.Params); err == nil {
+			values = params
+		}
+	}
+	if extra, err := envfile.Stringify(overrides); err == nil {
+		for key, value := range extra {
+			values[key] = value
+		}
+	}
+	return values
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"log/slog"
+	"solar-scope/database"
+	"solar-scope/internal/apierror"
+	"solar-scope/internal/auth"
+	"solar-scope/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// registerSiteRoutes, tenant'a ait saha rotalarını ekler.
+func registerSiteRoutes(apiV1 fiber.Router, viewer, operator fiber.Handler, pvRoutes *sitePV) {
+	sitesGroup := apiV1.Group("/sites", viewer)
+
+	sitesGroup.Get("/", func(c *fiber.Ctx) error {
+		sites, err := database.ListSites(auth.PrincipalFrom(c).TenantID)
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "error listing sites", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to list sites")
+		}
+		return c.Status(200).JSON(sites)
+	})
+
+	sitesGroup.Post("/", operator, func(c *fiber.Ctx) error {
+		var site models.Site
+		if err := c.BodyParser(&site); err != nil || site.Name == "" {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Invalid site payload, name is required")
+		}
+
+		// Sistem kimlikleri tenant_id ile saha oluşturabilir, diğerleri kendi tenant'ına
+		principal := auth.PrincipalFrom(c)
+		if !principal.IsSystem() {
+			site.TenantID = principal.TenantID
+		}
+		if site.TenantID == 0 {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "tenant_id is required")
+		}
+		site.ID = 0
+		if err := database.CreateSite(&site); err != nil {
+			slog.ErrorContext(c.UserContext(), "error creating site", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to create site")
+		}
+		return c.Status(201).JSON(site)
+	})
+
+	sitesGroup.Get("/:id", func(c *fiber.Ctx) error {
+		site, err := database.GetSite(auth.PrincipalFrom(c).TenantID, c.Params("id"))
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "error retrieving site", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve site")
+		}
+		if site == nil {
+			return apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "Site not found")
+		}
+		return c.Status(200).JSON(site)
+	})
+
+	sitesGroup.Delete("/:id", operator, func(c *fiber.Ctx) error {
+		found, err := database.DeleteSite(auth.PrincipalFrom(c).TenantID, c.Params("id"))
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "error deleting site", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to delete site")
+		}
+		if !found {
+			return apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "Site not found")
+		}
+		return c.Status(200).JSON(fiber.Map{
+			"status":  "success",
+			"message": "Site deleted",
+		})
+	})
+
+	pvRoutes.register(sitesGroup, operator)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"solar-scope/database"
+	"solar-scope/internal/apierror"
+	"solar-scope/internal/auth"
+	"solar-scope/internal/client"
+	"solar-scope/internal/snapshot"
+	"solar-scope/models"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// snapshotter, tahminlerin eğitim penceresinin anlık görüntüsünü alır.
+type snapshotter struct {
+	vm      *client.PrometheusClient
+	enabled bool
+	step    time.Duration
+}
+
+// wanted, isteğin anlık görüntü isteyip istemediğini söyler; ?snapshot
+// verilmezse TRAINING_SNAPSHOTS geçerlidir.
+func (s *snapshotter) wanted(c *fiber.Ctx) (bool, error) {
+	value := c.Query("snapshot")
+	if value == "" {
+		return s.enabled, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "snapshot must be a boolean")
+	}
+	return parsed, nil
+}
+
+// capture, metric'in eğitim penceresini saklar ve kaydın ID'sini döner.
+// Görüntü alınamazsa tahmin engellenmez; hata loglanır ve nil döner.
+func (s *snapshotter) capture(c *fiber.Ctx, metric string, trainDays int) *uint {
+	if metric == "" || trainDays <= 0 {
+		slog.WarnContext(c.UserContext(), "skipping training data snapshot, METRIC_NAME or TRAIN_DAYS is unknown")
+		return nil
+	}
+	pc, err := tenantVM(c, s.vm)
+	if err != nil {
+		slog.WarnContext(c.UserContext(), "skipping training data snapshot", "error", err)
+		return nil
+	}
+	snap, err := snapshot.Capture(c.UserContext(), pc, metric, trainDays, s.step)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "error capturing training data snapshot", "error", err)
+		return nil
+	}
+	record := &models.TrainingSnapshot{
+		TenantID:    auth.PrincipalFrom(c).TenantID,
+		ContentHash: snap.Hash,
+		Metric:      metric,
+		Start:       snap.Data.Start,
+		End:         snap.Data.End,
+		StepSeconds: snap.Data.StepSeconds,
+		Series:      len(snap.Data.Series),
+		Samples:     snap.Samples,
+		Encoding:    snapshot.Encoding,
+		Size:        snap.UncompressedSize,
+		StoredSize:  len(snap.Compressed),
+		Data:        snap.Compressed,
+	}
+	if err := database.SaveTrainingSnapshot(c.UserContext(), record); err != nil {
+		slog.ErrorContext(c.UserContext(), "error saving training data snapshot", "error", err)
+		return nil
+	}
+	slog.InfoContext(c.UserContext(), "training data snapshot saved",
+		"snapshot_id", record.ID, "hash", record.ContentHash, "samples", record.Samples, "stored_size", record.StoredSize)
+	return &record.ID
+}
+
+// registerSnapshotRoutes, eğitim verisi anlık görüntülerinin rotalarını ekler.
+func registerSnapshotRoutes(apiV1 fiber.Router, viewer fiber.Handler) {
+	snapshotsGroup := apiV1.Group("/snapshots", viewer)
+
+	// Anlık görüntünün özeti; içerik /download ile indirilir
+	snapshotsGroup.Get("/:id", func(c *fiber.Ctx) error {
+		snap, err := findSnapshot(c, false)
+		if snap == nil {
+			return err
+		}
+		return c.Status(200).JSON(snap)
+	})
+
+	// Sıkıştırılmış içeriği çevrimdışı analiz için indir
+	snapshotsGroup.Get("/:id/download", func(c *fiber.Ctx) error {
+		snap, err := findSnapshot(c, true)
+		if snap == nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, "application/gzip")
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="snapshot-%d.json.gz"`, snap.ID))
+		c.Set(fiber.HeaderETag, strconv.Quote(snap.ContentHash))
+		return c.Status(200).Send(snap.Data)
+	})
+}
+
+// findSnapshot, çağıranın tenant'ına ait anlık görüntüyü bulur. Bulunamazsa
+// hata yanıtını yazar ve nil döner.
+func findSnapshot(c *fiber.Ctx, withData bool) (*models.TrainingSnapshot, error) {
+	snap, err := database.GetTrainingSnapshot(c.UserContext(), auth.PrincipalFrom(c).TenantID, c.Params("id"), withData)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "error retrieving training data snapshot", "error", err)
+		return nil, apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve snapshot")
+	}
+	if snap == nil {
+		return nil, apierror.Write(c, fiber.StatusNotFound, apierror.CodeNotFound, "Snapshot not found")
+	}
+	return snap, nil
+}
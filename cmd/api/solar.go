@@ -0,0 +1,86 @@
+package main
+
+import (
+	"solar-scope/internal/apierror"
+	"solar-scope/internal/solar"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// registerSolarRoutes, güneş konumu ve gün ışığı rotalarını ekler.
+func registerSolarRoutes(apiV1 fiber.Router, viewer fiber.Handler) {
+	solarGroup := apiV1.Group("/solar", viewer)
+
+	// Güneşin konumu ve açık gök ışınımı; time verilmezse şimdiki an
+	solarGroup.Get("/position", func(c *fiber.Ctx) error {
+		at, err := coordinates(c)
+		if at == nil {
+			return err
+		}
+		when := time.Now()
+		if value := c.Query("time"); value != "" {
+			if when, err = time.Parse(time.RFC3339, value); err != nil {
+				return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "time must be an RFC 3339 timestamp")
+			}
+		}
+		altitude, err := strconv.ParseFloat(c.Query("altitude", "0"), 64)
+		if err != nil {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "altitude must be a number in metres")
+		}
+
+		pos := solar.SunPosition(at.Lat, at.Lon, when)
+		return c.Status(200).JSON(fiber.Map{
+			"latitude":  at.Lat,
+			"longitude": at.Lon,
+			"altitude":  altitude,
+			"position":  pos,
+			"air_mass":  solar.AirMass(pos.Zenith),
+			"clear_sky": solar.ClearSky(pos, altitude),
+		})
+	})
+
+	// Gün doğumu, güneş öğlesi ve batımı; date verilmezse bugün. Zamanlar
+	// tz'nin (IANA adı, varsayılan UTC) saat diliminde döner.
+	solarGroup.Get("/daylight", func(c *fiber.Ctx) error {
+		at, err := coordinates(c)
+		if at == nil {
+			return err
+		}
+		loc, err := time.LoadLocation(c.Query("tz", "UTC"))
+		if err != nil {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "tz must be an IANA time zone, e.g. Europe/Istanbul")
+		}
+		date := time.Now().In(loc)
+		if value := c.Query("date"); value != "" {
+			if date, err = time.ParseInLocation("2006-01-02", value, loc); err != nil {
+				return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "date must be in YYYY-MM-DD format")
+			}
+		}
+		return c.Status(200).JSON(fiber.Map{
+			"latitude":  at.Lat,
+			"longitude": at.Lon,
+			"daylight":  solar.SunTimes(at.Lat, at.Lon, date),
+		})
+	})
+}
+
+// location, sorgudaki enlem ve boylamdır.
+type location struct {
+	Lat, Lon float64
+}
+
+// coordinates, lat ve lon sorgu parametrelerini okur. Geçersizse hata
+// yanıtını yazar ve nil döner.
+func coordinates(c *fiber.Ctx) (*location, error) {
+	lat, latErr := strconv.ParseFloat(c.Query("lat"), 64)
+	lon, lonErr := strconv.ParseFloat(c.Query("lon"), 64)
+	if latErr != nil || lonErr != nil {
+		return nil, apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "lat and lon are required")
+	}
+	if err := solar.CheckCoordinates(lat, lon); err != nil {
+		return nil, apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, err.Error())
+	}
+	return &location{Lat: lat, Lon: lon}, nil
+}
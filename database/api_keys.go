@@ -0,0 +1,48 @@
+package database
+
+import (
+	"solar-scope/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CreateAPIKey, yeni bir API anahtarı kaydı oluşturur.
+func CreateAPIKey(key *models.APIKey) error {
+	return DB.Create(key).Error
+}
+
+// ListAPIKeys, tenant'a ait iptal edilmemiş API anahtarlarını getirir.
+func ListAPIKeys(tenantID uint) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := DB.Scopes(TenantScope(tenantID)).Order("created_at desc").Find(&keys).Error
+	return keys, err
+}
+
+// FindAPIKeyByHash, özeti verilen anahtarı getirir. Bulunamazsa nil döner.
+func FindAPIKeyByHash(hash string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := DB.Where("key_hash = ?", hash).First(&key).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// TouchAPIKey, anahtarın son kullanım zamanını günceller.
+func TouchAPIKey(id uint) error {
+	return DB.Model(&models.APIKey{}).Where("id = ?", id).
+		Update("last_used_at", time.Now()).Error
+}
+
+// DeleteAPIKey, anahtarı iptal eder. Anahtar yoksa false döner.
+func DeleteAPIKey(tenantID uint, id string) (bool, error) {
+	result := DB.Scopes(TenantScope(tenantID)).Where("id = ?", id).Delete(&models.APIKey{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
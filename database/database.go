@@ -1,15 +1,19 @@
 package database
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
+	"os"
 	"solar-scope/internal/config"
+	"solar-scope/internal/tracing"
 	"solar-scope/models"
 	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 var DB *gorm.DB
@@ -19,12 +23,25 @@ func Connect(cfg config.Config) {
 		cfg.DBHost, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBPort)
 
 	var err error
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: logger.NewSlogLogger(slog.Default(), logger.Config{
+			SlowThreshold:             200 * time.Millisecond,
+			LogLevel:                  logger.Warn,
+			IgnoreRecordNotFoundError: true,
+		}),
+	})
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		slog.Error("failed to connect to database", "error", err)
+		os.Exit(1)
 	}
 
-	log.Println("Database connection established")
+	slog.Info("database connection established")
+
+	// İstek context'iyle yapılan sorgular için span oluştur
+	if err := DB.Use(tracing.GormPlugin{}); err != nil {
+		slog.Error("failed to register tracing plugin", "error", err)
+		os.Exit(1)
+	}
 
 	// Modelleri otomatik olarak migrate et
 	err = DB.AutoMigrate(
@@ -32,15 +49,23 @@ func Connect(cfg config.Config) {
 		&models.EnergyBalance{},
 		&models.BatteryPerformance{},
 		&models.ActionRecommendation{},
+		&models.APIKey{},
+		&models.Tenant{},
+		&models.Site{},
+		&models.ForecasterSession{},
+		&models.EnvTemplate{},
+		&models.TrainingSnapshot{},
+		&models.IdempotencyKey{},
 	)
 	if err != nil {
-		log.Fatal("Migrate işlemi başarısız:", err)
+		slog.Error("database migration failed", "error", err)
+		os.Exit(1)
 	}
 
 }
 
-// SaveForecast, gelen payload'u veritabanına kaydeder
-func SaveForecast(payload models.ForecastPayload) (*models.Forecast, error) {
+// SaveForecast, gelen payload'u verilen tenant adına veritabanına kaydeder
+func SaveForecast(ctx context.Context, payload models.ForecastPayload, tenantID uint) (*models.Forecast, error) {
 	result := payload.Result
 	// Zamanı string olarak al ve time.Time'a dönüştür
 	parsedTime, err := time.Parse("2006-01-02T15:04:05.999999", payload.Timestamp)
@@ -55,10 +80,13 @@ func SaveForecast(payload models.ForecastPayload) (*models.Forecast, error) {
 	}
 
 	forecast := &models.Forecast{
+		TenantID:      tenantID,
 		SessionID:     payload.SessionID,
 		Timestamp:     parsedTime,
 		ForecastDate:  result.Date,
 		GeneralStatus: payload.GeneralStatus,
+		SnapshotID:    payload.SnapshotID,
+		Provider:      payload.Provider,
 		EnergyBalance: models.EnergyBalance{
 			TotalProductionKwh:  result.EnergyBalance.TotalProductionKwh,
 			TotalConsumptionKwh: result.EnergyBalance.TotalConsumptionKwh,
@@ -78,44 +106,47 @@ func SaveForecast(payload models.ForecastPayload) (*models.Forecast, error) {
 		ActionRecommendations: recommendations,
 	}
 
-	if err := DB.Create(forecast).Error; err != nil {
+	if err := DB.WithContext(ctx).Create(forecast).Error; err != nil {
 		return nil, err
 	}
 	return forecast, nil
 }
 
-func SaveResultToDB(result interface{}) {
+// SaveResultToDB, SolarForecaster yanıtını kaydeder ve kaydedilen tahmini döner.
+// Yanıt kaydedilemezse hatayı loglar ve nil döner.
+func SaveResultToDB(ctx context.Context, result interface{}, tenantID uint) *models.Forecast {
 	var dbPayload models.ForecastPayload
 
 	resultBytes, err := json.Marshal(result)
 	if err != nil {
-		log.Printf("Error marshaling result: %v", err)
-		return
+		slog.ErrorContext(ctx, "error marshaling result", "error", err)
+		return nil
 	}
 
 	err = json.Unmarshal(resultBytes, &dbPayload)
 	if err != nil {
-		log.Printf("Error unmarshaling to ForecastPayload: %v", err)
-		return
+		slog.ErrorContext(ctx, "error unmarshaling to ForecastPayload", "error", err)
+		return nil
 	}
 
 	if dbPayload.SessionID == "" {
-		log.Println("No session_id in result, skipping DB save")
-		return
+		slog.WarnContext(ctx, "no session_id in result, skipping DB save")
+		return nil
 	}
 
-	_, err = SaveForecast(dbPayload)
+	forecast, err := SaveForecast(ctx, dbPayload, tenantID)
 	if err != nil {
-		log.Printf("Error saving forecast to DB: %v", err)
-		return
+		slog.ErrorContext(ctx, "error saving forecast to DB", "error", err)
+		return nil
 	}
-	log.Println("Forecast saved to DB successfully")
+	slog.InfoContext(ctx, "forecast saved to DB", "forecast_id", forecast.ID, "session_id", forecast.SessionID)
+	return forecast
 }
 
 // son tahminleri getirir
-func GetRecentForecasts(limit int) ([]models.Forecast, error) {
+func GetRecentForecasts(ctx context.Context, tenantID uint, limit int) ([]models.Forecast, error) {
 	var forecasts []models.Forecast
-	err := DB.Limit(limit).Order("timestamp desc").
+	err := DB.WithContext(ctx).Scopes(TenantScope(tenantID)).Limit(limit).Order("timestamp desc").
 		Preload("EnergyBalance").
 		Preload("BatteryPerformance").
 		Preload("ActionRecommendations").
@@ -124,9 +155,9 @@ func GetRecentForecasts(limit int) ([]models.Forecast, error) {
 }
 
 // ID'ye göre belirli bir tahmini getirir
-func GetForecastByID(id string) (*models.Forecast, error) {
+func GetForecastByID(ctx context.Context, tenantID uint, id string) (*models.Forecast, error) {
 	var forecast models.Forecast
-	err := DB.Where("id = ?", id).
+	err := DB.WithContext(ctx).Scopes(TenantScope(tenantID)).Where("id = ?", id).
 		Preload("EnergyBalance").
 		Preload("BatteryPerformance").
 		Preload("ActionRecommendations").
@@ -0,0 +1,26 @@
+package database
+
+import (
+	"solar-scope/models"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupTestDB, testin adına özel bellek içi bir SQLite veritabanını DB'ye bağlar.
+func setupTestDB(t *testing.T) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.Tenant{}, &models.Site{}, &models.ForecasterSession{},
+		&models.Forecast{}, &models.EnergyBalance{}, &models.BatteryPerformance{}, &models.ActionRecommendation{}); err != nil {
+		t.Fatal(err)
+	}
+	DB = db
+}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"solar-scope/models"
+
+	"gorm.io/gorm"
+)
+
+// CreateEnvTemplate, şablonu tenant'taki aynı adlı şablonun bir sonraki
+// sürümü olarak kaydeder.
+func CreateEnvTemplate(ctx context.Context, template *models.EnvTemplate) error {
+	return DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var latest int
+		err := tx.Model(&models.EnvTemplate{}).
+			Where("tenant_id = ? AND name = ?", template.TenantID, template.Name).
+			Select("COALESCE(MAX(version), 0)").Scan(&latest).Error
+		if err != nil {
+			return err
+		}
+		template.Version = latest + 1
+		return tx.Create(template).Error
+	})
+}
+
+// ListEnvTemplates, tenant'ın şablonlarının yalnızca son sürümlerini ada
+// göre sıralı getirir.
+func ListEnvTemplates(ctx context.Context, tenantID uint) ([]models.EnvTemplate, error) {
+	var all []models.EnvTemplate
+	err := DB.WithContext(ctx).Scopes(TenantScope(tenantID)).
+		Order("name, tenant_id, version DESC").Find(&all).Error
+	if err != nil {
+		return nil, err
+	}
+	latest := []models.EnvTemplate{}
+	for i, template := range all {
+		if i > 0 && all[i-1].Name == template.Name && all[i-1].TenantID == template.TenantID {
+			continue
+		}
+		latest = append(latest, template)
+	}
+	return latest, nil
+}
+
+// GetEnvTemplate, tenant'a ait şablon sürümünü ID ile getirir. Bulunamazsa
+// nil döner.
+func GetEnvTemplate(ctx context.Context, tenantID uint, id string) (*models.EnvTemplate, error) {
+	var template models.EnvTemplate
+	err := DB.WithContext(ctx).Scopes(TenantScope(tenantID)).Where("id = ?", id).First(&template).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &template, nil
+}
+
+// ListEnvTemplateVersions, şablonun tüm sürümlerini en yeniden eskiye getirir.
+func ListEnvTemplateVersions(ctx context.Context, template *models.EnvTemplate) ([]models.EnvTemplate, error) {
+	var versions []models.EnvTemplate
+	err := DB.WithContext(ctx).
+		Where("tenant_id = ? AND name = ?", template.TenantID, template.Name).
+		Order("version DESC").Find(&versions).Error
+	return versions, err
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"solar-scope/models"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// ClaimIdempotencyKey, anahtarı işlenmekte olarak kaydetmeye çalışır. Anahtar
+// süresi dolmamış bir kayıtta zaten varsa o kayıt döner ve çağıran isteği
+// işlememelidir; nil dönerse anahtar çağıranındır. Süresi dolan kayıtlar
+// bu sırada temizlenir.
+func ClaimIdempotencyKey(ctx context.Context, tenantID uint, key, requestHash string, ttl time.Duration) (*models.IdempotencyKey, error) {
+	db := DB.WithContext(ctx)
+	now := time.Now()
+	if err := db.Unscoped().Where("expires_at < ?", now).Delete(&models.IdempotencyKey{}).Error; err != nil {
+		return nil, err
+	}
+
+	record := models.IdempotencyKey{
+		TenantID:    tenantID,
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   now.Add(ttl),
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return nil, nil
+	}
+
+	var existing models.IdempotencyKey
+	err := db.Where(map[string]interface{}{"tenant_id": tenantID, "key": key}).First(&existing).Error
+	if err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
+// TakeOverIdempotencyKey, lockedBefore'dan önce kilitlenip hiç tamamlanmamış
+// (terk edilmiş) bir anahtarı tek bir koşullu güncellemeyle çağırana devreder.
+// Aynı anda gelen isteklerden yalnızca biri true alır.
+func TakeOverIdempotencyKey(ctx context.Context, tenantID uint, key, requestHash string, ttl time.Duration, lockedBefore time.Time) (bool, error) {
+	now := time.Now()
+	result := DB.WithContext(ctx).Model(&models.IdempotencyKey{}).
+		Where(map[string]interface{}{"tenant_id": tenantID, "key": key, "status_code": 0}).
+		Where("created_at < ?", lockedBefore).
+		Updates(map[string]interface{}{
+			"request_hash": requestHash,
+			"created_at":   now,
+			"expires_at":   now.Add(ttl),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// CompleteIdempotencyKey, işlenen isteğin yanıtını anahtara yazar.
+func CompleteIdempotencyKey(ctx context.Context, tenantID uint, key string, statusCode int, contentType string, response []byte) error {
+	return DB.WithContext(ctx).Model(&models.IdempotencyKey{}).
+		Where(map[string]interface{}{"tenant_id": tenantID, "key": key}).
+		Updates(map[string]interface{}{
+			"status_code":  statusCode,
+			"content_type": contentType,
+			"response":     response,
+		}).Error
+}
+
+// ReleaseIdempotencyKey, anahtarı siler; aynı anahtarla yeniden denenebilir.
+func ReleaseIdempotencyKey(ctx context.Context, tenantID uint, key string) error {
+	return DB.WithContext(ctx).Unscoped().
+		Where(map[string]interface{}{"tenant_id": tenantID, "key": key}).
+		Delete(&models.IdempotencyKey{}).Error
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"solar-scope/internal/metrics"
+	"solar-scope/models"
+	"sync"
+)
+
+// pendingSaves, arka planda devam eden kayıt işlemlerini izler. closing,
+// WaitForSaves çağrıldıktan sonra yeni kayıtları reddeder; savesMu, closing
+// denetimiyle pendingSaves.Add'in kapanışın başlamasıyla yarışmamasını sağlar.
+var (
+	pendingSaves sync.WaitGroup
+	savesMu      sync.Mutex
+	closing      bool
+)
+
+// SaveResultAsync, sonucu arka planda kaydeder. Kayıt başarılı olursa
+// onSaved kaydedilen tahminle çağrılır. Kapanışta WaitForSaves ile beklenir;
+// kapanış başladıktan sonra gelen sonuçlar, bağlantı kapanırken yazılmasın
+// diye kaydedilmez. Kayıt isteğin bitmesiyle iptal edilmesin diye ctx'in
+// yalnızca değerleri (istek kimliği, trace) kullanılır.
+func SaveResultAsync(ctx context.Context, result interface{}, tenantID uint, onSaved func(*models.Forecast)) {
+	ctx = context.WithoutCancel(ctx)
+	done := metrics.SaveStarted()
+	savesMu.Lock()
+	if closing {
+		savesMu.Unlock()
+		done(false)
+		slog.WarnContext(ctx, "shutdown in progress, forecast not saved", "tenant_id", tenantID)
+		return
+	}
+	pendingSaves.Add(1)
+	savesMu.Unlock()
+	go func() {
+		defer pendingSaves.Done()
+		forecast := SaveResultToDB(ctx, result, tenantID)
+		done(forecast != nil)
+		if forecast != nil && onSaved != nil {
+			onSaved(forecast)
+		}
+	}()
+}
+
+// WaitForSaves, yeni kayıtları kapatır ve bekleyenlerin bitmesini veya ctx'in
+// dolmasını bekler.
+func WaitForSaves(ctx context.Context) error {
+	savesMu.Lock()
+	closing = true
+	savesMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		pendingSaves.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("pending forecast saves did not finish: %w", ctx.Err())
+	}
+}
+
+// Close, veritabanı bağlantı havuzunu kapatır.
+func Close() error {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database pool: %w", err)
+	}
+	return sqlDB.Close()
+}
+
+// Ping, veritabanı bağlantısının canlı olduğunu doğrular.
+func Ping(ctx context.Context) error {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database pool: %w", err)
+	}
+	return sqlDB.PingContext(ctx)
+}
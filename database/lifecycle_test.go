@@ -0,0 +1,42 @@
+package database
+
+import (
+	"context"
+	"solar-scope/models"
+	"testing"
+	"time"
+)
+
+func TestSaveResultAsyncAfterShutdown(t *testing.T) {
+	setupTestDB(t)
+	t.Cleanup(func() { closing = false })
+
+	result := map[string]interface{}{
+		"session_id": "s1",
+		"timestamp":  "2025-01-01T12:00:00.000000",
+		"result":     map[string]interface{}{"date": "2025-01-01"},
+	}
+	saved := make(chan *models.Forecast, 2)
+	onSaved := func(f *models.Forecast) { saved <- f }
+
+	SaveResultAsync(context.Background(), result, 1, onSaved)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := WaitForSaves(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 1 {
+		t.Fatalf("%d forecasts saved before shutdown, want 1", len(saved))
+	}
+
+	// Kapanış başladıktan sonraki kayıt bağlantının kapanmasıyla yarışmamalı
+	SaveResultAsync(context.Background(), result, 1, onSaved)
+	if err := WaitForSaves(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	DB.Model(&models.Forecast{}).Count(&count)
+	if count != 1 || len(saved) != 1 {
+		t.Errorf("%d forecasts stored and %d reported after shutdown, want 1 and 1", count, len(saved))
+	}
+}
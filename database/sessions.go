@@ -0,0 +1,206 @@
+package database
+
+import (
+	"context"
+	"solar-scope/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SessionSyncReport, yerel session kayıtlarının upstream listesiyle
+// karşılaştırılmasının sonucudur.
+type SessionSyncReport struct {
+	Synced       int       `json:"synced"`
+	LocalOnly    []string  `json:"local_only"`
+	UpstreamOnly []string  `json:"upstream_only"`
+	CheckedAt    time.Time `json:"checked_at"`
+}
+
+// RecordSession, yeni oluşturulan bir session'ı kaydeder. Senkronizasyon
+// session'ı daha önce upstream_only olarak eklediyse kayıt güncellenir.
+func RecordSession(ctx context.Context, session *models.ForecasterSession) error {
+	session.SyncState = models.SessionSynced
+	return DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "session_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"tenant_id", "owner", "site_id", "backend", "params", "sync_state", "ttl_seconds", "template_id", "updated_at"}),
+	}).Create(session).Error
+}
+
+// SessionBelongsTo, session'ın verilen tenant'a ait olup olmadığını döner.
+func SessionBelongsTo(tenantID uint, sessionID string) (bool, error) {
+	if tenantID == 0 {
+		return true, nil
+	}
+	var count int64
+	err := DB.Model(&models.ForecasterSession{}).
+		Where("session_id = ? AND tenant_id = ?", sessionID, tenantID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ListSessionIDs, tenant'a ait session ID'lerini getirir.
+func ListSessionIDs(tenantID uint) ([]string, error) {
+	var ids []string
+	err := DB.Model(&models.ForecasterSession{}).Scopes(TenantScope(tenantID)).
+		Pluck("session_id", &ids).Error
+	return ids, err
+}
+
+// ListSessions, tenant'ın session kayıtlarını en yeniden eskiye getirir.
+// state veya siteID boş değilse kayıtlar bunlara göre süzülür.
+func ListSessions(ctx context.Context, tenantID uint, state, siteID string) ([]models.ForecasterSession, error) {
+	query := DB.WithContext(ctx).Scopes(TenantScope(tenantID))
+	if state != "" {
+		query = query.Where("sync_state = ?", state)
+	}
+	if siteID != "" {
+		query = query.Where("site_id = ?", siteID)
+	}
+	var sessions []models.ForecasterSession
+	err := query.Order("created_at DESC").Find(&sessions).Error
+	return sessions, err
+}
+
+// GetSession, tenant'a ait session kaydını getirir. Bulunamazsa nil döner.
+func GetSession(ctx context.Context, tenantID uint, sessionID string) (*models.ForecasterSession, error) {
+	var session models.ForecasterSession
+	err := DB.WithContext(ctx).Scopes(TenantScope(tenantID)).
+		Where("session_id = ?", sessionID).First(&session).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// TouchSession, session'ın son kullanım zamanını günceller.
+func TouchSession(ctx context.Context, sessionID string) error {
+	return DB.WithContext(ctx).Model(&models.ForecasterSession{}).
+		Where("session_id = ?", sessionID).
+		Update("last_used_at", time.Now()).Error
+}
+
+// ExpiredSession, süresi dolmuş bir session kaydı ve uygulanan süredir.
+type ExpiredSession struct {
+	models.ForecasterSession
+	TTL time.Duration
+}
+
+// ExpiredSessions, son etkinliğinden bu yana TTL'i dolmuş session'ları getirir.
+// Süre sırasıyla session'ın, tenant'ın ve defaultTTL'in değeridir; geçerli
+// süresi 0 olan session'lar hiç silinmez. solar-scope dışında oluşturulan
+// upstream_only session'lar başka istemcilere ait olabileceğinden seçilmez.
+func ExpiredSessions(ctx context.Context, defaultTTL time.Duration, now time.Time) ([]ExpiredSession, error) {
+	var tenants []models.Tenant
+	if err := DB.WithContext(ctx).Select("id", "session_ttl_seconds").Find(&tenants).Error; err != nil {
+		return nil, err
+	}
+	tenantTTL := make(map[uint]time.Duration, len(tenants))
+	for _, t := range tenants {
+		tenantTTL[t.ID] = time.Duration(t.SessionTTLSeconds) * time.Second
+	}
+
+	var sessions []models.ForecasterSession
+	err := DB.WithContext(ctx).
+		Where("sync_state <> ?", models.SessionUpstreamOnly).
+		Order("created_at").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	var expired []ExpiredSession
+	for _, session := range sessions {
+		ttl := time.Duration(session.TTLSeconds) * time.Second
+		if ttl <= 0 {
+			ttl = tenantTTL[session.TenantID]
+		}
+		if ttl <= 0 {
+			ttl = defaultTTL
+		}
+		if ttl > 0 && now.Sub(session.LastActivity()) > ttl {
+			expired = append(expired, ExpiredSession{ForecasterSession: session, TTL: ttl})
+		}
+	}
+	return expired, nil
+}
+
+// DeleteSessionRecord, session kaydını siler.
+func DeleteSessionRecord(sessionID string) error {
+	return DB.Unscoped().Where("session_id = ?", sessionID).Delete(&models.ForecasterSession{}).Error
+}
+
+// SyncSessions, yerel kayıtları upstream session listesiyle (session ID ->
+// backend) karşılaştırır. Upstream'de görülen kayıtlar synced olur, görülmeyenler
+// local_only olarak işaretlenir; yerelde olmayan upstream session'ları sahipsiz
+// (tenant 0) upstream_only kayıtları olarak eklenir.
+func SyncSessions(ctx context.Context, upstream map[string]string) (SessionSyncReport, error) {
+	now := time.Now()
+	report := SessionSyncReport{LocalOnly: []string{}, UpstreamOnly: []string{}, CheckedAt: now}
+
+	err := DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var local []models.ForecasterSession
+		if err := tx.Find(&local).Error; err != nil {
+			return err
+		}
+
+		known := make(map[string]bool, len(local))
+		for _, session := range local {
+			known[session.SessionID] = true
+			backend, ok := upstream[session.SessionID]
+			if !ok {
+				report.LocalOnly = append(report.LocalOnly, session.SessionID)
+				if session.SyncState != models.SessionLocalOnly {
+					if err := tx.Model(&session).Update("sync_state", models.SessionLocalOnly).Error; err != nil {
+						return err
+					}
+				}
+				continue
+			}
+
+			state := models.SessionSynced
+			if session.SyncState == models.SessionUpstreamOnly {
+				state = models.SessionUpstreamOnly
+				report.UpstreamOnly = append(report.UpstreamOnly, session.SessionID)
+			} else {
+				report.Synced++
+			}
+			if err := tx.Model(&session).Updates(map[string]interface{}{
+				"sync_state":   state,
+				"backend":      backend,
+				"last_seen_at": now,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		for sessionID, backend := range upstream {
+			if known[sessionID] {
+				continue
+			}
+			orphan := models.ForecasterSession{
+				SessionID:  sessionID,
+				Backend:    backend,
+				LastSeenAt: &now,
+				SyncState:  models.SessionUpstreamOnly,
+			}
+			// RecordSession aynı session'ı bu arada kaydetmiş olabilir; çakışma
+			// tüm senkronizasyonu geri almamalı
+			result := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "session_id"}},
+				DoNothing: true,
+			}).Create(&orphan)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 {
+				report.UpstreamOnly = append(report.UpstreamOnly, sessionID)
+			}
+		}
+		return nil
+	})
+	return report, err
+}
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"slices"
+	"solar-scope/models"
+	"testing"
+	"time"
+)
+
+func TestSyncSessions(t *testing.T) {
+	setupTestDB(t)
+	ctx := context.Background()
+	for _, s := range []models.ForecasterSession{
+		{SessionID: "synced", TenantID: 1, SyncState: models.SessionSynced},
+		{SessionID: "gone", TenantID: 1, SyncState: models.SessionSynced},
+	} {
+		if err := RecordSession(ctx, &s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := SyncSessions(ctx, map[string]string{"synced": "b1", "foreign": "b2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Synced != 1 || !slices.Equal(report.LocalOnly, []string{"gone"}) || !slices.Equal(report.UpstreamOnly, []string{"foreign"}) {
+		t.Errorf("report = %+v", report)
+	}
+
+	var orphan models.ForecasterSession
+	if err := DB.Where("session_id = ?", "foreign").First(&orphan).Error; err != nil {
+		t.Fatal(err)
+	}
+	if orphan.TenantID != 0 || orphan.SyncState != models.SessionUpstreamOnly || orphan.Backend != "b2" {
+		t.Errorf("orphan = %+v", orphan)
+	}
+}
+
+// Sync listeyi okuduktan sonra eklenen bir kayıt, yetim eklemesiyle
+// çakışsa da senkronizasyonu başarısız kılmamalı.
+func TestSyncSessionsIgnoresConcurrentRecord(t *testing.T) {
+	setupTestDB(t)
+	ctx := context.Background()
+	// Silinmiş kayıt Find'da görünmez ama benzersiz indeksle çakışır; eşzamanlı
+	// bir RecordSession'ın etkisini taklit eder
+	raced := models.ForecasterSession{SessionID: "raced", TenantID: 1, SyncState: models.SessionSynced}
+	if err := DB.Create(&raced).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := DB.Delete(&raced).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := SyncSessions(ctx, map[string]string{"raced": "b1", "foreign": "b1"})
+	if err != nil {
+		t.Fatalf("sync aborted by conflict: %v", err)
+	}
+	if !slices.Equal(report.UpstreamOnly, []string{"foreign"}) {
+		t.Errorf("upstream_only = %v, want [foreign]", report.UpstreamOnly)
+	}
+}
+
+func TestExpiredSessionsSkipsUpstreamOnly(t *testing.T) {
+	setupTestDB(t)
+	ctx := context.Background()
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	for _, s := range []models.ForecasterSession{
+		{SessionID: "mine", TenantID: 1, SyncState: models.SessionSynced},
+		{SessionID: "foreign", SyncState: models.SessionUpstreamOnly},
+		{SessionID: "fresh", TenantID: 1, SyncState: models.SessionSynced},
+	} {
+		if err := DB.Create(&s).Error; err != nil {
+			t.Fatal(err)
+		}
+		if s.SessionID != "fresh" {
+			DB.Model(&s).Update("created_at", old)
+		}
+	}
+
+	expired, err := ExpiredSessions(ctx, 7*24*time.Hour, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, s := range expired {
+		ids = append(ids, s.SessionID)
+	}
+	if !slices.Equal(ids, []string{"mine"}) {
+		t.Errorf("expired = %v, want [mine]", ids)
+	}
+}
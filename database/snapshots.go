@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+	"solar-scope/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SaveTrainingSnapshot, anlık görüntüyü kaydeder. Tenant'ta aynı hash'e sahip
+// bir kayıt varsa yenisi eklenmez ve snapshot mevcut kaydın bilgileriyle
+// doldurulur.
+func SaveTrainingSnapshot(ctx context.Context, snapshot *models.TrainingSnapshot) error {
+	return DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "content_hash"}},
+			DoNothing: true,
+		}).Create(snapshot).Error
+		if err != nil || snapshot.ID != 0 {
+			return err
+		}
+		return tx.Omit("data").
+			Where("tenant_id = ? AND content_hash = ?", snapshot.TenantID, snapshot.ContentHash).
+			First(snapshot).Error
+	})
+}
+
+// GetTrainingSnapshot, tenant'a ait anlık görüntüyü ID ile getirir. withData
+// false ise sıkıştırılmış içerik yüklenmez. Bulunamazsa nil döner.
+func GetTrainingSnapshot(ctx context.Context, tenantID uint, id string, withData bool) (*models.TrainingSnapshot, error) {
+	var snapshot models.TrainingSnapshot
+	query := DB.WithContext(ctx).Scopes(TenantScope(tenantID)).Where("id = ?", id)
+	if !withData {
+		query = query.Omit("data")
+	}
+	if err := query.First(&snapshot).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &snapshot, nil
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"solar-scope/models"
+
+	"gorm.io/gorm"
+)
+
+// TenantScope, sorguyu verilen tenant'ın kayıtlarıyla sınırlar.
+// tenantID 0 ise (sistem yöneticisi) sorgu sınırlandırılmaz.
+func TenantScope(tenantID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if tenantID == 0 {
+			return db
+		}
+		return db.Where("tenant_id = ?", tenantID)
+	}
+}
+
+// CreateTenant, yeni bir tenant oluşturur.
+func CreateTenant(tenant *models.Tenant) error {
+	return DB.Create(tenant).Error
+}
+
+// ListTenants, tüm tenant'ları getirir.
+func ListTenants() ([]models.Tenant, error) {
+	var tenants []models.Tenant
+	err := DB.Order("name").Find(&tenants).Error
+	return tenants, err
+}
+
+// SetTenantSessionTTL, tenant'ın session TTL'ini günceller. Tenant yoksa
+// false döner.
+func SetTenantSessionTTL(id uint, seconds int64) (bool, error) {
+	result := DB.Model(&models.Tenant{}).Where("id = ?", id).Update("session_ttl_seconds", seconds)
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetTenant, ID'ye göre tenant'ı getirir. Bulunamazsa nil döner.
+func GetTenant(id uint) (*models.Tenant, error) {
+	var tenant models.Tenant
+	err := DB.First(&tenant, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+// CreateSite, yeni bir saha kaydı oluşturur.
+func CreateSite(site *models.Site) error {
+	return DB.Create(site).Error
+}
+
+// ListSites, tenant'a ait sahaları getirir.
+func ListSites(tenantID uint) ([]models.Site, error) {
+	var sites []models.Site
+	err := DB.Scopes(TenantScope(tenantID)).Order("name").Find(&sites).Error
+	return sites, err
+}
+
+// GetSite, tenant'a ait sahayı ID ile getirir. Bulunamazsa nil döner.
+func GetSite(tenantID uint, id string) (*models.Site, error) {
+	var site models.Site
+	err := DB.Scopes(TenantScope(tenantID)).Where("id = ?", id).First(&site).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &site, nil
+}
+
+// SetSitePV, tenant'a ait sahanın panel dizisini değiştirir ve güncel
+// sahayı döner. Saha yoksa nil döner.
+func SetSitePV(tenantID uint, id string, pv models.PVSystem) (*models.Site, error) {
+	site, err := GetSite(tenantID, id)
+	if err != nil || site == nil {
+		return nil, err
+	}
+	site.PV = pv
+	// Sıfır değerler de yazılsın diye sütunlar açıkça seçilir
+	err = DB.Model(site).Select(
+		"pv_latitude", "pv_longitude", "pv_altitude_m", "pv_capacity_kwp", "pv_tilt_deg", "pv_azimuth_deg",
+		"pv_temp_coefficient", "pv_system_losses", "pv_inverter_efficiency", "pv_dc_ac_ratio",
+		"pv_production_metric", "pv_irradiance_metric",
+	).Updates(site).Error
+	if err != nil {
+		return nil, err
+	}
+	return site, nil
+}
+
+// DeleteSite, tenant'a ait sahayı siler. Saha yoksa false döner.
+func DeleteSite(tenantID uint, id string) (bool, error) {
+	result := DB.Scopes(TenantScope(tenantID)).Where("id = ?", id).Delete(&models.Site{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
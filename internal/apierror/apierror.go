@@ -0,0 +1,140 @@
+package apierror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"solar-scope/internal/client"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Code, hatanın makine tarafından okunabilir türüdür.
+type Code string
+
+const (
+	CodeBadRequest          Code = "bad_request"
+	CodeUnauthorized        Code = "unauthorized"
+	CodeForbidden           Code = "forbidden"
+	CodeNotFound            Code = "not_found"
+	CodeConflict            Code = "conflict"
+	CodeTooManyRequests     Code = "too_many_requests"
+	CodeDataQuality         Code = "insufficient_data_quality"
+	CodeInternal            Code = "internal_error"
+	CodeUpstreamError       Code = "upstream_error"
+	CodeUpstreamUnavailable Code = "upstream_unavailable"
+	CodeUpstreamTimeout     Code = "upstream_timeout"
+	CodeClientClosedRequest Code = "client_closed_request"
+)
+
+// StatusClientClosedRequest, istemcinin yanıtı beklemeden bağlantıyı
+// kapattığı istekler için kullanılan (nginx'teki gibi) durum kodudur.
+const StatusClientClosedRequest = 499
+
+// Response, tüm hata yanıtlarının ortak zarfıdır. Status geriye dönük
+// uyumluluk için her zaman "error" değerini taşır.
+type Response struct {
+	Status         string      `json:"status"`
+	Code           Code        `json:"code"`
+	Message        string      `json:"message"`
+	UpstreamStatus int         `json:"upstream_status,omitempty"`
+	Details        interface{} `json:"details,omitempty"`
+}
+
+// Write, verilen HTTP durumu ve kodla bir hata yanıtı yazar.
+func Write(c *fiber.Ctx, status int, code Code, message string) error {
+	return c.Status(status).JSON(Response{
+		Status:  "error",
+		Code:    code,
+		Message: message,
+	})
+}
+
+// WriteDetails, Write gibidir ancak yanıta ayrıntı ekler.
+func WriteDetails(c *fiber.Ctx, status int, code Code, message string, details interface{}) error {
+	return c.Status(status).JSON(Response{
+		Status:  "error",
+		Code:    code,
+		Message: message,
+		Details: details,
+	})
+}
+
+// Upstream, bir SolarForecaster çağrısının hatasını yanıta çevirir.
+// Upstream 4xx yanıtları aynı durumla, mesajı ve ayrıntılarıyla iletilir;
+// upstream'in kendi kimlik hataları (401/403) çağıranın suçu olmadığından
+// 502 olarak döner. 5xx yanıtlarında upstream mesajı sızdırılmaz, fallback
+// kullanılır. Devre açıksa 503, upstream dışı hatalar 500 olur.
+func Upstream(c *fiber.Ctx, err error, fallback string) error {
+	var ue *client.UpstreamError
+	if !errors.As(err, &ue) {
+		return Write(c, fiber.StatusInternalServerError, CodeInternal, fallback)
+	}
+
+	switch {
+	case errors.Is(ue.Err, client.ErrCircuitOpen):
+		return Write(c, fiber.StatusServiceUnavailable, CodeUpstreamUnavailable, "Forecaster is temporarily unavailable, try again later")
+	case ue.Status == 0 && errors.Is(ue.Err, context.Canceled):
+		// İstemci bağlantıyı kapattı; yanıt yalnızca log ve metriklere yansır
+		return Write(c, StatusClientClosedRequest, CodeClientClosedRequest, "Client closed the request")
+	case ue.Status == 0 && errors.Is(ue.Err, context.DeadlineExceeded):
+		return Write(c, fiber.StatusGatewayTimeout, CodeUpstreamTimeout, "Forecaster did not respond in time")
+	case ue.Status == 0:
+		return Write(c, fiber.StatusBadGateway, CodeUpstreamUnavailable, "Forecaster is unreachable")
+	case ue.Status >= 400 && ue.Status < 500 &&
+		ue.Status != http.StatusUnauthorized && ue.Status != http.StatusForbidden:
+		message := ue.Message
+		if message == "" {
+			message = fallback
+		}
+		return c.Status(ue.Status).JSON(Response{
+			Status:         "error",
+			Code:           codeForStatus(ue.Status),
+			Message:        message,
+			UpstreamStatus: ue.Status,
+			Details:        ue.Details,
+		})
+	default:
+		return c.Status(fiber.StatusBadGateway).JSON(Response{
+			Status:         "error",
+			Code:           CodeUpstreamError,
+			Message:        fallback,
+			UpstreamStatus: ue.Status,
+		})
+	}
+}
+
+// codeForStatus, bir 4xx durumunu koda eşler.
+func codeForStatus(status int) Code {
+	switch status {
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusTooManyRequests:
+		return CodeTooManyRequests
+	default:
+		return CodeBadRequest
+	}
+}
+
+// Handler, Fiber'ın varsayılan hata işleyicisinin yerini alır; eşleşmeyen
+// rotalar ve handler'lardan dönen hatalar da aynı zarfla yanıtlanır.
+func Handler(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	message := "Internal server error"
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		status = fiberErr.Code
+		message = fiberErr.Message
+	}
+	code := CodeInternal
+	if status < fiber.StatusInternalServerError {
+		code = codeForStatus(status)
+	}
+	return Write(c, status, code, message)
+}
@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"log/slog"
+	"solar-scope/database"
+	"solar-scope/internal/apierror"
+	"solar-scope/internal/config"
+	"solar-scope/models"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Roller, yetki sırasına göre tanımlanır: admin > operator > viewer.
+const (
+	RoleViewer   = "viewer"
+	RoleOperator = "operator"
+	RoleAdmin    = "admin"
+)
+
+var roleRank = map[string]int{
+	RoleViewer:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// ValidRole, verilen rolün tanımlı olup olmadığını döner.
+func ValidRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
+// Principal, isteği yapan kimliği temsil eder. TenantID 0 olan kimlikler
+// sistem genelindedir ve tenant'lar arası erişebilir.
+type Principal struct {
+	Subject  string         `json:"subject"`
+	Role     string         `json:"role"`
+	Method   string         `json:"method"` // api_key, jwt, bootstrap veya disabled
+	TenantID uint           `json:"tenant_id"`
+	Tenant   *models.Tenant `json:"-"`
+}
+
+// IsSystem, kimliğin herhangi bir tenant'a bağlı olmadığını döner.
+func (p *Principal) IsSystem() bool {
+	return p != nil && p.TenantID == 0
+}
+
+// HasRole, kimliğin en az verilen rol kadar yetkili olup olmadığını döner.
+func (p *Principal) HasRole(role string) bool {
+	return p != nil && roleRank[p.Role] >= roleRank[role]
+}
+
+const principalKey = "auth.principal"
+
+// PrincipalFrom, Authenticate tarafından isteğe eklenen kimliği döner.
+func PrincipalFrom(c *fiber.Ctx) *Principal {
+	p, _ := c.Locals(principalKey).(*Principal)
+	return p
+}
+
+// Authenticate, X-API-Key veya Authorization: Bearer başlığındaki kimlik
+// bilgisini doğrular ve kimliği isteğe ekler. Kimlik bilgisi olmayan
+// istekler geçer; yetki kontrolü Require ile yapılır.
+func Authenticate(cfg config.Config) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !cfg.AuthEnabled {
+			c.Locals(principalKey, &Principal{Subject: "anonymous", Role: RoleAdmin, Method: "disabled"})
+			return c.Next()
+		}
+
+		credential := c.Get("X-API-Key")
+		if credential == "" {
+			header := c.Get(fiber.HeaderAuthorization)
+			if len(header) > 7 && strings.EqualFold(header[:7], "bearer ") {
+				credential = strings.TrimSpace(header[7:])
+			}
+		}
+		if credential == "" {
+			return c.Next()
+		}
+
+		principal, err := resolve(c.UserContext(), cfg, credential)
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "error resolving credential", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to verify credentials")
+		}
+		if principal == nil {
+			return apierror.Write(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "Invalid credentials")
+		}
+
+		c.Locals(principalKey, principal)
+		return c.Next()
+	}
+}
+
+// resolve, kimlik bilgisini sırasıyla bootstrap anahtarı, JWT ve
+// veritabanındaki API anahtarlarıyla eşleştirir.
+func resolve(ctx context.Context, cfg config.Config, credential string) (*Principal, error) {
+	if cfg.AdminAPIKey != "" && subtle.ConstantTimeCompare([]byte(credential), []byte(cfg.AdminAPIKey)) == 1 {
+		return &Principal{Subject: "bootstrap", Role: RoleAdmin, Method: "bootstrap"}, nil
+	}
+
+	if strings.Count(credential, ".") == 2 {
+		if cfg.JWTSecret == "" {
+			return nil, nil
+		}
+		claims, err := ParseJWT(credential, []byte(cfg.JWTSecret))
+		if err != nil || !ValidRole(claims.Role) {
+			return nil, nil
+		}
+		return withTenant(&Principal{Subject: claims.Subject, Role: claims.Role, Method: "jwt", TenantID: claims.TenantID})
+	}
+
+	key, err := database.FindAPIKeyByHash(HashKey(credential))
+	if err != nil || key == nil {
+		return nil, err
+	}
+	if err := database.TouchAPIKey(key.ID); err != nil {
+		slog.WarnContext(ctx, "error updating API key usage", "error", err)
+	}
+	return withTenant(&Principal{Subject: key.Name, Role: key.Role, Method: "api_key", TenantID: key.TenantID})
+}
+
+// withTenant, kimliğin bağlı olduğu tenant'ı yükler. Tenant silinmişse
+// kimlik geçersiz sayılır.
+func withTenant(p *Principal) (*Principal, error) {
+	if p.TenantID == 0 {
+		return p, nil
+	}
+	tenant, err := database.GetTenant(p.TenantID)
+	if err != nil || tenant == nil {
+		return nil, err
+	}
+	p.Tenant = tenant
+	return p, nil
+}
+
+// RequireSystem, isteği yapan kimliğin tenant'a bağlı olmayan bir admin
+// olmasını şart koşar.
+func RequireSystem() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal := PrincipalFrom(c)
+		if principal == nil {
+			return apierror.Write(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "Authentication required")
+		}
+		if !principal.IsSystem() || !principal.HasRole(RoleAdmin) {
+			return apierror.Write(c, fiber.StatusForbidden, apierror.CodeForbidden, "System admin required")
+		}
+		return c.Next()
+	}
+}
+
+// Require, isteği yapan kimliğin en az verilen role sahip olmasını şart koşar.
+func Require(role string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal := PrincipalFrom(c)
+		if principal == nil {
+			return apierror.Write(c, fiber.StatusUnauthorized, apierror.CodeUnauthorized, "Authentication required")
+		}
+		if !principal.HasRole(role) {
+			return apierror.Write(c, fiber.StatusForbidden, apierror.CodeForbidden, "Insufficient role, "+role+" required")
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"net/http/httptest"
+	"solar-scope/database"
+	"solar-scope/internal/config"
+	"solar-scope/models"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const adminKey = "bootstrap-admin-key"
+
+var testConfig = config.Config{AuthEnabled: true, AdminAPIKey: adminKey, JWTSecret: string(secret)}
+
+// setupDB, testin adına özel bellek içi bir veritabanını bağlar ve bir
+// tenant ile anahtarlarını ekler.
+func setupDB(t *testing.T) *models.Tenant {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.Tenant{}, &models.APIKey{}); err != nil {
+		t.Fatal(err)
+	}
+	database.DB = db
+
+	tenant := &models.Tenant{Name: "Kadıköy", Slug: "kadikoy", LabelName: "ilce", LabelValue: "kadikoy"}
+	db.Create(tenant)
+	db.Create(&models.APIKey{Name: "tenant-key", KeyHash: HashKey("ss_tenant"), Role: RoleViewer, TenantID: tenant.ID})
+	db.Create(&models.APIKey{Name: "system-key", KeyHash: HashKey("ss_system"), Role: RoleAdmin})
+	// Bootstrap anahtarı ve JWT biçimli değerler veritabanından önce çözülür
+	db.Create(&models.APIKey{Name: "shadow-bootstrap", KeyHash: HashKey(adminKey), Role: RoleViewer, TenantID: tenant.ID})
+	db.Create(&models.APIKey{Name: "shadow-jwt", KeyHash: HashKey("a.b.c"), Role: RoleAdmin})
+	return tenant
+}
+
+func TestResolve(t *testing.T) {
+	tenant := setupDB(t)
+	exp := time.Now().Add(time.Hour).Unix()
+
+	tests := []struct {
+		name       string
+		cfg        config.Config
+		credential string
+		want       *Principal // nil: geçersiz
+	}{
+		{"bootstrap key wins over a stored key", testConfig, adminKey, &Principal{Subject: "bootstrap", Role: RoleAdmin, Method: "bootstrap"}},
+		{"bootstrap key unset", config.Config{JWTSecret: string(secret)}, adminKey, &Principal{Subject: "shadow-bootstrap", Role: RoleViewer, Method: "api_key", TenantID: tenant.ID}},
+		{"system jwt", testConfig, token(Claims{Subject: "ops", Role: RoleOperator, ExpiresAt: exp}), &Principal{Subject: "ops", Role: RoleOperator, Method: "jwt"}},
+		{"tenant jwt", testConfig, token(Claims{Subject: "ops", Role: RoleViewer, TenantID: tenant.ID, ExpiresAt: exp}), &Principal{Subject: "ops", Role: RoleViewer, Method: "jwt", TenantID: tenant.ID}},
+		{"jwt for a deleted tenant", testConfig, token(Claims{Subject: "ops", Role: RoleViewer, TenantID: 999, ExpiresAt: exp}), nil},
+		{"jwt with unknown role", testConfig, token(Claims{Subject: "ops", Role: "root", ExpiresAt: exp}), nil},
+		{"expired jwt", testConfig, token(Claims{Subject: "ops", Role: RoleAdmin, ExpiresAt: 1}), nil},
+		{"jwt-shaped value never falls back to stored keys", testConfig, "a.b.c", nil},
+		{"jwt without a secret", config.Config{}, "a.b.c", nil},
+		{"stored tenant key", testConfig, "ss_tenant", &Principal{Subject: "tenant-key", Role: RoleViewer, Method: "api_key", TenantID: tenant.ID}},
+		{"stored system key", testConfig, "ss_system", &Principal{Subject: "system-key", Role: RoleAdmin, Method: "api_key"}},
+		{"unknown key", testConfig, "ss_unknown", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolve(context.Background(), tt.cfg, tt.credential)
+			if err != nil {
+				t.Fatalf("resolve() error = %v", err)
+			}
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("resolve() = %+v, want invalid", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("resolve() = nil, want a principal")
+			}
+			if got.Subject != tt.want.Subject || got.Role != tt.want.Role || got.Method != tt.want.Method || got.TenantID != tt.want.TenantID {
+				t.Errorf("resolve() = %+v, want %+v", got, tt.want)
+			}
+			if got.TenantID != 0 && (got.Tenant == nil || got.Tenant.ID != got.TenantID) {
+				t.Errorf("resolve() did not load tenant %d", got.TenantID)
+			}
+		})
+	}
+
+	var key models.APIKey
+	database.DB.Where("name = ?", "system-key").First(&key)
+	if key.LastUsedAt == nil {
+		t.Error("resolve() did not record the key's last use")
+	}
+}
+
+func TestRequire(t *testing.T) {
+	tenant := setupDB(t)
+	exp := time.Now().Add(time.Hour).Unix()
+
+	app := fiber.New()
+	app.Use(Authenticate(testConfig))
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) }
+	app.Get("/viewer", Require(RoleViewer), ok)
+	app.Get("/operator", Require(RoleOperator), ok)
+	app.Get("/system", RequireSystem(), ok)
+
+	tenantAdmin := token(Claims{Subject: "t", Role: RoleAdmin, TenantID: tenant.ID, ExpiresAt: exp})
+	systemOperator := token(Claims{Subject: "s", Role: RoleOperator, ExpiresAt: exp})
+	tests := []struct {
+		name   string
+		path   string
+		header string
+		value  string
+		want   int
+	}{
+		{"no credentials", "/viewer", "", "", fiber.StatusUnauthorized},
+		{"no credentials on system route", "/system", "", "", fiber.StatusUnauthorized},
+		{"invalid key", "/viewer", "X-API-Key", "ss_unknown", fiber.StatusUnauthorized},
+		{"invalid bearer", "/viewer", "Authorization", "Bearer " + tamper(tenantAdmin), fiber.StatusUnauthorized},
+		{"viewer key", "/viewer", "X-API-Key", "ss_tenant", fiber.StatusOK},
+		{"viewer below operator", "/operator", "X-API-Key", "ss_tenant", fiber.StatusForbidden},
+		{"bearer token", "/operator", "Authorization", "bearer " + systemOperator, fiber.StatusOK},
+		{"tenant admin on system route", "/system", "Authorization", "Bearer " + tenantAdmin, fiber.StatusForbidden},
+		{"system operator on system route", "/system", "Authorization", "Bearer " + systemOperator, fiber.StatusForbidden},
+		{"system admin key", "/system", "X-API-Key", "ss_system", fiber.StatusOK},
+		{"bootstrap key", "/system", "X-API-Key", adminKey, fiber.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Errorf("GET %s = %d, want %d", tt.path, resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthDisabled(t *testing.T) {
+	app := fiber.New()
+	app.Use(Authenticate(config.Config{}))
+	app.Get("/system", RequireSystem(), func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	resp, err := app.Test(httptest.NewRequest("GET", "/system", nil))
+	if err != nil || resp.StatusCode != fiber.StatusOK {
+		t.Errorf("GET /system with auth disabled = %v, %v, want 200", resp.StatusCode, err)
+	}
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Claims, solar-scope'un kabul ettiği JWT alanlarıdır.
+type Claims struct {
+	Subject   string `json:"sub"`
+	Role      string `json:"role"`
+	TenantID  uint   `json:"tenant_id,omitempty"`
+	ExpiresAt int64  `json:"exp"`
+	NotBefore int64  `json:"nbf,omitempty"`
+}
+
+var (
+	errMalformedToken = errors.New("malformed token")
+	errBadSignature   = errors.New("invalid token signature")
+	errTokenExpired   = errors.New("token expired")
+)
+
+// ParseJWT, HS256 ile imzalanmış bir JWT'yi doğrular ve içeriğini döner.
+// exp alanı zorunludur.
+func ParseJWT(token string, secret []byte) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errMalformedToken
+	}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errMalformedToken
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(headerBytes, &header); err != nil || header.Alg != "HS256" {
+		return nil, errMalformedToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errMalformedToken
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, errBadSignature
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errMalformedToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, errMalformedToken
+	}
+
+	now := time.Now().Unix()
+	if claims.ExpiresAt == 0 || now >= claims.ExpiresAt {
+		return nil, errTokenExpired
+	}
+	if claims.NotBefore != 0 && now < claims.NotBefore {
+		return nil, errTokenExpired
+	}
+	return &claims, nil
+}
@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var secret = []byte("test-secret")
+
+// sign, verilen başlık ve içerikle HS256 imzalı bir JWT üretir.
+func sign(header, claims interface{}, key []byte) string {
+	encode := func(v interface{}) string {
+		raw, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(raw)
+	}
+	unsigned := encode(header) + "." + encode(claims)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func token(claims Claims) string {
+	return sign(map[string]string{"alg": "HS256", "typ": "JWT"}, claims, secret)
+}
+
+func TestParseJWT(t *testing.T) {
+	now := time.Now().Unix()
+	valid := Claims{Subject: "ops", Role: RoleOperator, TenantID: 7, ExpiresAt: now + 60}
+	parts := strings.Split(token(valid), ".")
+	unsigned := parts[0] + "." + parts[1] + "."
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"valid", token(valid), nil},
+		{"past nbf", token(Claims{Role: RoleViewer, ExpiresAt: now + 60, NotBefore: now - 1}), nil},
+		{"alg none", sign(map[string]string{"alg": "none"}, valid, secret), errMalformedToken},
+		{"alg HS512", sign(map[string]string{"alg": "HS512"}, valid, secret), errMalformedToken},
+		{"lowercase alg", sign(map[string]string{"alg": "hs256"}, valid, secret), errMalformedToken},
+		{"no alg", sign(map[string]string{}, valid, secret), errMalformedToken},
+		{"empty signature", unsigned, errBadSignature},
+		{"wrong secret", sign(map[string]string{"alg": "HS256"}, valid, []byte("other")), errBadSignature},
+		{"tampered claims", tamper(token(valid)), errBadSignature},
+		{"expired", token(Claims{Role: RoleViewer, ExpiresAt: now - 1}), errTokenExpired},
+		{"expires now", token(Claims{Role: RoleViewer, ExpiresAt: now}), errTokenExpired},
+		{"missing exp", token(Claims{Role: RoleViewer}), errTokenExpired},
+		{"not yet valid", token(Claims{Role: RoleViewer, ExpiresAt: now + 60, NotBefore: now + 30}), errTokenExpired},
+		{"two parts", "a.b", errMalformedToken},
+		{"invalid base64", "!!.b.c", errMalformedToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := ParseJWT(tt.token, secret)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("ParseJWT() error = %v, want %v", err, tt.want)
+			}
+			if err == nil && claims == nil {
+				t.Fatal("ParseJWT() returned no claims")
+			}
+			if err != nil && claims != nil {
+				t.Errorf("ParseJWT() returned claims %+v with an error", claims)
+			}
+		})
+	}
+
+	claims, _ := ParseJWT(token(valid), secret)
+	if *claims != valid {
+		t.Errorf("ParseJWT() = %+v, want %+v", claims, valid)
+	}
+}
+
+// tamper, imzayı koruyarak içeriği admin rolüyle değiştirir.
+func tamper(jwt string) string {
+	parts := strings.Split(jwt, ".")
+	raw, _ := json.Marshal(Claims{Subject: "ops", Role: RoleAdmin, ExpiresAt: time.Now().Unix() + 60})
+	return parts[0] + "." + base64.RawURLEncoding.EncodeToString(raw) + "." + parts[2]
+}
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// keyPrefix, solar-scope anahtarlarını diğer gizli değerlerden ayırt etmeye yarar.
+const keyPrefix = "ss_"
+
+// GenerateKey, yeni bir rastgele API anahtarı üretir.
+func GenerateKey() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	return keyPrefix + hex.EncodeToString(buf), nil
+}
+
+// HashKey, anahtarın veritabanında saklanan SHA-256 özetini döner.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// DisplayPrefix, anahtarı listelerde tanımak için ilk karakterlerini döner.
+func DisplayPrefix(key string) string {
+	if len(key) <= 11 {
+		return key
+	}
+	return key[:11]
+}
@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateKey(t *testing.T) {
+	a, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := GenerateKey()
+	if !strings.HasPrefix(a, keyPrefix) || len(a) != len(keyPrefix)+64 || a == b {
+		t.Errorf("GenerateKey() = %q, %q, want distinct ss_ keys with 32 random bytes", a, b)
+	}
+	if HashKey(a) != HashKey(a) || HashKey(a) == HashKey(b) || len(HashKey(a)) != 64 {
+		t.Error("HashKey() should be a stable SHA-256 hex digest")
+	}
+	if DisplayPrefix(a) != a[:11] || DisplayPrefix("short") != "short" {
+		t.Errorf("DisplayPrefix(%q) = %q", a, DisplayPrefix(a))
+	}
+}
@@ -0,0 +1,175 @@
+// Package baseline, Python forecaster'a ulaşılamadığında kullanılabilecek
+// basit tahmin yöntemlerini içerir. Sonuçlar forecaster'ın ForecastPayload
+// biçimindedir.
+package baseline
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// Tahmin yöntemleri.
+const (
+	MethodPersistence   = "persistence"      // Son ölçülen değer ufuk boyunca sabit kalır
+	MethodSeasonalNaive = "seasonal_naive"   // Her nokta verinin olduğu son günün aynı saatidir
+	MethodClearSky      = "clear_sky_scaled" // Açık gök zarfı son 24 saatin açık gök indeksiyle ölçeklenir
+)
+
+// Horizon, tahmin ufkudur.
+const Horizon = 24 * time.Hour
+
+// envelopeQuantile, açık gök zarfı için gün içi her dilimde alınan yüzdeliktir;
+// tek bir parlama zarfı bozmasın diye en büyük değer yerine kullanılır.
+const envelopeQuantile = 0.9
+
+// ErrNoHistory, geçmişte hiç örnek yoksa döner.
+var ErrNoHistory = errors.New("no production history in the training window")
+
+// Point, bir zaman noktasındaki panel gücüdür (W).
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// Production, ufuk boyunca beklenen üretimdir.
+type Production struct {
+	Method string
+	// ClearSkyIndex, MethodClearSky'da son 24 saatin zarfa oranıdır.
+	ClearSkyIndex float64
+	Points        []Point
+}
+
+// Methods, desteklenen yöntemleri döner.
+func Methods() []string {
+	return []string{MethodPersistence, MethodSeasonalNaive, MethodClearSky}
+}
+
+// Choose, geçmişin uzunluğuna göre yöntem seçer: en az üç gün için açık gök
+// ölçekleme, en az bir gün için mevsimsel naif, aksi halde kalıcılık.
+func Choose(history []Point) string {
+	if len(history) < 2 {
+		return MethodPersistence
+	}
+	span := history[len(history)-1].Time.Sub(history[0].Time)
+	switch {
+	case span >= 3*24*time.Hour:
+		return MethodClearSky
+	case span >= 24*time.Hour:
+		return MethodSeasonalNaive
+	default:
+		return MethodPersistence
+	}
+}
+
+// Predict, from'dan itibaren Horizon boyunca step aralıklarla üretimi tahmin
+// eder. history zamana göre sıralı ve step'e hizalı olmalıdır. method boşsa
+// Choose ile seçilir.
+func Predict(history []Point, from time.Time, step time.Duration, method string) (Production, error) {
+	if len(history) == 0 {
+		return Production{}, ErrNoHistory
+	}
+	if method == "" {
+		method = Choose(history)
+	}
+	var times []time.Time
+	for t := from.Add(step); !t.After(from.Add(Horizon)); t = t.Add(step) {
+		times = append(times, t)
+	}
+
+	production := Production{Method: method, Points: make([]Point, len(times))}
+	switch method {
+	case MethodPersistence:
+		last := history[len(history)-1].Value
+		for i, t := range times {
+			production.Points[i] = Point{Time: t, Value: last}
+		}
+	case MethodSeasonalNaive:
+		byTime := index(history)
+		for i, t := range times {
+			production.Points[i] = Point{Time: t, Value: sameTimeLastDay(byTime, t, history[0].Time)}
+		}
+	case MethodClearSky:
+		envelope := clearSkyEnvelope(history, step)
+		production.ClearSkyIndex = clearSkyIndex(history, envelope, from, step)
+		for i, t := range times {
+			production.Points[i] = Point{Time: t, Value: production.ClearSkyIndex * envelope[slot(t, step)]}
+		}
+	default:
+		return Production{}, fmt.Errorf("unknown baseline method %q", method)
+	}
+	return production, nil
+}
+
+func index(history []Point) map[int64]float64 {
+	byTime := make(map[int64]float64, len(history))
+	for _, p := range history {
+		byTime[p.Time.Unix()] = p.Value
+	}
+	return byTime
+}
+
+// sameTimeLastDay, t'den geriye doğru gün gün giderek verinin olduğu ilk
+// günün aynı saatindeki değeri döner; hiç yoksa 0.
+func sameTimeLastDay(byTime map[int64]float64, t, oldest time.Time) float64 {
+	for day := t.Add(-24 * time.Hour); !day.Before(oldest); day = day.Add(-24 * time.Hour) {
+		if v, ok := byTime[day.Unix()]; ok {
+			return v
+		}
+	}
+	return 0
+}
+
+// slot, t'nin gün içindeki step'lik dilimidir.
+func slot(t time.Time, step time.Duration) int {
+	daySeconds := int64(24 * time.Hour / time.Second)
+	return int((t.Unix() % daySeconds) / int64(step/time.Second))
+}
+
+// clearSkyEnvelope, gün içi her dilim için geçmişteki yüksek üretimi bulur;
+// bulutsuz bir günün ampirik profilidir.
+func clearSkyEnvelope(history []Point, step time.Duration) []float64 {
+	slots := int(24*time.Hour/step) + 1
+	values := make([][]float64, slots)
+	for _, p := range history {
+		s := slot(p.Time, step)
+		values[s] = append(values[s], p.Value)
+	}
+	envelope := make([]float64, slots)
+	for s, v := range values {
+		if len(v) > 0 {
+			envelope[s] = math.Max(0, quantile(v, envelopeQuantile))
+		}
+	}
+	return envelope
+}
+
+// clearSkyIndex, from'dan önceki 24 saatte ölçülen üretimin zarfa oranıdır.
+// [0, 1] aralığına sınırlanır; zarfın boş olduğu durumda 1 döner.
+func clearSkyIndex(history []Point, envelope []float64, from time.Time, step time.Duration) float64 {
+	var actual, expected float64
+	for _, p := range history {
+		if p.Time.Before(from.Add(-24 * time.Hour)) {
+			continue
+		}
+		if e := envelope[slot(p.Time, step)]; e > 0 {
+			actual += math.Max(0, p.Value)
+			expected += e
+		}
+	}
+	if expected == 0 {
+		return 1
+	}
+	return math.Min(1, actual/expected)
+}
+
+func quantile(values []float64, q float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+}
@@ -0,0 +1,122 @@
+package baseline
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+var from = time.Date(2025, 6, 3, 0, 0, 0, 0, time.UTC)
+
+// hourly, from'dan önceki hours saatlik geçmişi value ile üretir.
+func hourly(hours int, value func(t time.Time) float64) []Point {
+	var history []Point
+	for t := from.Add(-time.Duration(hours) * time.Hour); !t.After(from); t = t.Add(time.Hour) {
+		history = append(history, Point{Time: t, Value: value(t)})
+	}
+	return history
+}
+
+func TestChoose(t *testing.T) {
+	tests := []struct {
+		hours int
+		want  string
+	}{
+		{0, MethodPersistence},
+		{23, MethodPersistence},
+		{24, MethodSeasonalNaive},
+		{71, MethodSeasonalNaive},
+		{72, MethodClearSky},
+	}
+	for _, tt := range tests {
+		if got := Choose(hourly(tt.hours, func(time.Time) float64 { return 1 })); got != tt.want {
+			t.Errorf("Choose(%dh) = %s, want %s", tt.hours, got, tt.want)
+		}
+	}
+}
+
+func TestPredictPersistence(t *testing.T) {
+	history := hourly(5, func(t time.Time) float64 { return float64(t.Hour()) })
+	production, err := Predict(history, from, time.Hour, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if production.Method != MethodPersistence || len(production.Points) != 24 {
+		t.Fatalf("Predict() = %s with %d points, want persistence with 24", production.Method, len(production.Points))
+	}
+	first, last := production.Points[0], production.Points[23]
+	if !first.Time.Equal(from.Add(time.Hour)) || !last.Time.Equal(from.Add(Horizon)) || first.Value != 0 || last.Value != 0 {
+		t.Errorf("Predict() points = %+v ... %+v, want the last value from +1h to +24h", first, last)
+	}
+}
+
+func TestPredictSeasonalNaive(t *testing.T) {
+	// Son günün 10:00 verisi eksik; bir önceki günün değeri kullanılır
+	history := hourly(48, func(t time.Time) float64 { return float64(t.Day()*100 + t.Hour()) })
+	var gapped []Point
+	for _, p := range history {
+		if !p.Time.Equal(from.Add(-14 * time.Hour)) {
+			gapped = append(gapped, p)
+		}
+	}
+	production, err := Predict(gapped, from, time.Hour, MethodSeasonalNaive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range production.Points {
+		want := float64(2*100 + p.Time.Hour())
+		switch {
+		case p.Time.Hour() == 0:
+			want = 300 // from'un kendisi
+		case p.Time.Hour() == 10:
+			want = 110
+		}
+		if p.Value != want {
+			t.Errorf("Predict() at %s = %v, want %v", p.Time.Format(time.Kitchen), p.Value, want)
+		}
+	}
+}
+
+func TestPredictClearSky(t *testing.T) {
+	// Son 24 saat önceki günlerin yarısı kadar üretmiş
+	history := hourly(72, func(t time.Time) float64 {
+		if t.After(from.Add(-24 * time.Hour)) {
+			return float64(t.Hour()) / 2
+		}
+		return float64(t.Hour())
+	})
+	production, err := Predict(history, from, time.Hour, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if production.Method != MethodClearSky || math.Abs(production.ClearSkyIndex-0.5) > 1e-9 {
+		t.Fatalf("Predict() = %s with index %v, want clear_sky_scaled with 0.5", production.Method, production.ClearSkyIndex)
+	}
+	for _, p := range production.Points {
+		if want := float64(p.Time.Hour()) / 2; math.Abs(p.Value-want) > 1e-9 {
+			t.Errorf("Predict() at %s = %v, want %v", p.Time.Format(time.Kitchen), p.Value, want)
+		}
+	}
+}
+
+func TestPredictErrors(t *testing.T) {
+	if _, err := Predict(nil, from, time.Hour, ""); !errors.Is(err, ErrNoHistory) {
+		t.Errorf("Predict() without history error = %v, want ErrNoHistory", err)
+	}
+	if _, err := Predict(hourly(1, func(time.Time) float64 { return 1 }), from, time.Hour, "magic"); err == nil {
+		t.Error("Predict() accepted an unknown method")
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	values := []float64{4, 1, 3, 2, 5}
+	for q, want := range map[float64]float64{0: 1, 0.5: 3, 0.9: 4.6, 1: 5} {
+		if got := quantile(values, q); math.Abs(got-want) > 1e-9 {
+			t.Errorf("quantile(%v) = %v, want %v", q, got, want)
+		}
+	}
+	if values[0] != 4 {
+		t.Error("quantile() sorted its input")
+	}
+}
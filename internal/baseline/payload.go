@@ -0,0 +1,219 @@
+package baseline
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"solar-scope/internal/client"
+	"time"
+)
+
+// Sonucun neden baseline ile üretildiği.
+const (
+	ReasonOnDemand    = "on_demand"    // Çağıran baseline istedi
+	ReasonCircuitOpen = "circuit_open" // Forecaster'ın devre kesicisi açık
+)
+
+// TimestampLayout, forecaster'ın timestamp biçimidir.
+const TimestampLayout = "2006-01-02T15:04:05.999999"
+
+// Params, batarya simülasyonunun girdileridir. Alan adları forecaster'ın env
+// anahtarlarıyla, sınırları /run ile aynıdır.
+type Params struct {
+	Method     string `json:"METHOD" validate:"oneof=persistence seasonal_naive clear_sky_scaled"`
+	MetricName string `json:"METRIC_NAME" validate:"promql"`
+	client.BatteryParams
+}
+
+// WithDefaults, verilmeyen verim değerlerini 0.9 yapar.
+func (p Params) WithDefaults() Params {
+	if p.ChargeEfficiency == 0 {
+		p.ChargeEfficiency = 0.9
+	}
+	if p.DischargeEfficiency == 0 {
+		p.DischargeEfficiency = 0.9
+	}
+	return p
+}
+
+// Info, sonucu baseline olarak işaretleyen ek bilgidir.
+type Info struct {
+	Method         string       `json:"method"`
+	Reason         string       `json:"reason"`
+	ClearSkyIndex  float64      `json:"clear_sky_index,omitempty"`
+	HistorySamples int          `json:"history_samples"`
+	StepSeconds    int64        `json:"step_seconds"`
+	Points         []PointState `json:"points"`
+}
+
+// PointState, ufuktaki bir noktanın beklenen üretimi ve batarya durumudur.
+type PointState struct {
+	Time        time.Time `json:"time"`
+	ProductionW float64   `json:"production_w"`
+	SocPercent  float64   `json:"soc_percent"`
+}
+
+// payload, ForecastPayload'un JSON biçimidir; sonucu tanımlayan bir
+// "baseline" nesnesiyle birlikte döner.
+type payload struct {
+	SessionID     string `json:"session_id"`
+	Timestamp     string `json:"timestamp"`
+	GeneralStatus string `json:"general_status"`
+	Result        Result `json:"result"`
+	Baseline      Info   `json:"baseline"`
+}
+
+// Result, ForecastPayload'un result alanıdır.
+type Result struct {
+	Date                  string             `json:"date"`
+	ActionRecommendations []string           `json:"action_recommendations"`
+	BatteryPerformance    BatteryPerformance `json:"battery_performance"`
+	EnergyBalance         EnergyBalance      `json:"energy_balance"`
+}
+
+// BatteryPerformance, ufuk boyunca batarya doluluğunun özetidir (yüzde).
+type BatteryPerformance struct {
+	InitialSoc         float64 `json:"initial_soc"`
+	MinSoc             float64 `json:"min_soc"`
+	MinSocTime         string  `json:"min_soc_time"`
+	MaxSoc             float64 `json:"max_soc"`
+	MaxSocTime         string  `json:"max_soc_time"`
+	EndOfDaySoc        float64 `json:"end_of_day_soc"`
+	TimeToFull         string  `json:"time_to_full"`
+	FullChargeExpected bool    `json:"full_charge_expected"`
+}
+
+// EnergyBalance, ufuk boyunca üretim ve tüketimin özetidir.
+type EnergyBalance struct {
+	TotalProductionKwh  float64 `json:"total_production_kwh"`
+	TotalConsumptionKwh float64 `json:"total_consumption_kwh"`
+	NetBatteryChangeWh  float64 `json:"net_battery_change_wh"`
+	StatusDescription   string  `json:"status_description"`
+}
+
+// Simulate, step aralıklı üretim noktaları boyunca sabit yük altında
+// bataryayı simüle eder. Fazla üretim bataryayı şarj eder, açık bataryadan
+// karşılanır.
+func Simulate(points []Point, from time.Time, step time.Duration, p Params) (Result, []PointState) {
+	p = p.WithDefaults()
+	hours := step.Hours()
+	capacity := p.BatteryCapacityWh
+	soc := capacity * p.InitialSocPercent / 100
+
+	battery := BatteryPerformance{
+		InitialSoc: round(p.InitialSocPercent),
+		MinSoc:     round(p.InitialSocPercent),
+		MinSocTime: from.Format("2006-01-02 15:04"),
+		MaxSoc:     round(p.InitialSocPercent),
+		MaxSocTime: from.Format("2006-01-02 15:04"),
+	}
+	states := make([]PointState, len(points))
+	var producedWh float64
+	for i, point := range points {
+		produced := math.Max(0, point.Value)
+		producedWh += produced * hours
+		net := (produced - p.ConstantLoadW) * hours
+		if net > 0 {
+			soc = math.Min(capacity, soc+net*p.ChargeEfficiency)
+		} else if p.DischargeEfficiency > 0 {
+			soc = math.Max(0, soc+net/p.DischargeEfficiency)
+		}
+		percent := 0.0
+		if capacity > 0 {
+			percent = soc / capacity * 100
+		}
+		if percent < battery.MinSoc {
+			battery.MinSoc, battery.MinSocTime = round(percent), point.Time.Format("2006-01-02 15:04")
+		}
+		if percent > battery.MaxSoc {
+			battery.MaxSoc, battery.MaxSocTime = round(percent), point.Time.Format("2006-01-02 15:04")
+		}
+		if !battery.FullChargeExpected && capacity > 0 && soc >= capacity {
+			battery.FullChargeExpected = true
+			battery.TimeToFull = point.Time.Sub(from).Truncate(time.Minute).String()
+		}
+		states[i] = PointState{Time: point.Time, ProductionW: round(produced), SocPercent: round(percent)}
+	}
+	if capacity > 0 {
+		battery.EndOfDaySoc = round(soc / capacity * 100)
+	}
+
+	balance := EnergyBalance{
+		TotalProductionKwh:  round(producedWh / 1000),
+		TotalConsumptionKwh: round(p.ConstantLoadW * hours * float64(len(points)) / 1000),
+		NetBatteryChangeWh:  round(soc - capacity*p.InitialSocPercent/100),
+	}
+	if balance.TotalProductionKwh >= balance.TotalConsumptionKwh {
+		balance.StatusDescription = "Energy surplus expected over the forecast horizon"
+	} else {
+		balance.StatusDescription = "Energy deficit expected over the forecast horizon"
+	}
+
+	recs := []string{}
+	if battery.MinSoc < 20 {
+		recs = append(recs, fmt.Sprintf("Battery expected to drop to %.0f%% at %s; reduce load before then", battery.MinSoc, battery.MinSocTime))
+	}
+	if battery.FullChargeExpected {
+		recs = append(recs, fmt.Sprintf("Battery expected to be full in %s; shift flexible loads to that period", battery.TimeToFull))
+	}
+	return Result{
+		Date:                  from.Format("2006-01-02"),
+		ActionRecommendations: recs,
+		BatteryPerformance:    battery,
+		EnergyBalance:         balance,
+	}, states
+}
+
+// Payload, üretim tahmini üzerinde bataryayı simüle eder ve sonucu
+// ForecastPayload biçiminde döner. sessionID boşsa "baseline-" önekli bir
+// kimlik üretilir; tahmin ancak session_id ile kaydedilir.
+func Payload(production Production, from time.Time, step time.Duration, p Params, reason, sessionID string, historySamples int) map[string]interface{} {
+	if sessionID == "" {
+		sessionID = NewSessionID("baseline")
+	}
+	result, states := Simulate(production.Points, from, step, p)
+
+	label := fmt.Sprintf("Baseline forecast (%s); treat it as a rough estimate", production.Method)
+	if reason == ReasonCircuitOpen {
+		label = fmt.Sprintf("ML forecaster unavailable, showing a baseline forecast (%s)", production.Method)
+	}
+	result.ActionRecommendations = append([]string{label}, result.ActionRecommendations...)
+
+	return ToMap(payload{
+		SessionID:     sessionID,
+		Timestamp:     from.Format(TimestampLayout),
+		GeneralStatus: "baseline",
+		Result:        result,
+		Baseline: Info{
+			Method:         production.Method,
+			Reason:         reason,
+			ClearSkyIndex:  round(production.ClearSkyIndex),
+			HistorySamples: historySamples,
+			StepSeconds:    int64(step / time.Second),
+			Points:         states,
+		},
+	})
+}
+
+// ToMap, v'yi forecaster yanıtı gibi bir map'e çevirir; işleyiciler sonuca
+// alan eklediğinden sonuçlar map olarak taşınır.
+func ToMap(v interface{}) map[string]interface{} {
+	raw, _ := json.Marshal(v)
+	var m map[string]interface{}
+	_ = json.Unmarshal(raw, &m)
+	return m
+}
+
+// NewSessionID, forecaster dışında üretilen tahminler için prefix önekli
+// rastgele bir session kimliği döner.
+func NewSessionID(prefix string) string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return prefix + "-" + hex.EncodeToString(b)
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,92 @@
+package baseline
+
+import (
+	"reflect"
+	"solar-scope/internal/client"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSimulate(t *testing.T) {
+	// 1000 Wh batarya %50 dolu, 100 W sabit yük, varsayılan %90 verim
+	params := Params{BatteryParams: client.BatteryParams{BatteryCapacityWh: 1000, InitialSocPercent: 50, ConstantLoadW: 100}}
+	var points []Point
+	for i, w := range []float64{600, 600, 0, 0} {
+		points = append(points, Point{Time: from.Add(time.Duration(i+1) * time.Hour), Value: w})
+	}
+	result, states := Simulate(points, from, time.Hour, params)
+
+	// 500 + 450 = 950, 950 + 450 = 1000 (dolu), 1000 - 100/0.9, 888.89 - 100/0.9
+	soc := []float64{95, 100, 88.89, 77.78}
+	for i, state := range states {
+		if state.SocPercent != soc[i] {
+			t.Errorf("state %d SoC = %v, want %v", i, state.SocPercent, soc[i])
+		}
+	}
+	want := Result{
+		Date: "2025-06-03",
+		ActionRecommendations: []string{
+			"Battery expected to be full in 2h0m0s; shift flexible loads to that period",
+		},
+		BatteryPerformance: BatteryPerformance{
+			InitialSoc:         50,
+			MinSoc:             50,
+			MinSocTime:         "2025-06-03 00:00",
+			MaxSoc:             100,
+			MaxSocTime:         "2025-06-03 02:00",
+			EndOfDaySoc:        77.78,
+			TimeToFull:         "2h0m0s",
+			FullChargeExpected: true,
+		},
+		EnergyBalance: EnergyBalance{
+			TotalProductionKwh:  1.2,
+			TotalConsumptionKwh: 0.4,
+			NetBatteryChangeWh:  277.78,
+			StatusDescription:   "Energy surplus expected over the forecast horizon",
+		},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("Simulate() = %+v, want %+v", result, want)
+	}
+}
+
+func TestSimulateLowBattery(t *testing.T) {
+	params := Params{BatteryParams: client.BatteryParams{BatteryCapacityWh: 1000, InitialSocPercent: 20, ConstantLoadW: 90, DischargeEfficiency: 1}}
+	points := []Point{{Time: from.Add(time.Hour)}, {Time: from.Add(2 * time.Hour)}, {Time: from.Add(3 * time.Hour), Value: -50}}
+	result, _ := Simulate(points, from, time.Hour, params)
+	battery := result.BatteryPerformance
+	if battery.MinSoc != 0 || battery.MinSocTime != "2025-06-03 03:00" || result.EnergyBalance.TotalProductionKwh != 0 {
+		t.Errorf("Simulate() = %+v, want the battery empty at 03:00 and negative production ignored", result)
+	}
+	if len(result.ActionRecommendations) != 1 || !strings.HasPrefix(result.ActionRecommendations[0], "Battery expected to drop to 0% at 2025-06-03 03:00") {
+		t.Errorf("recommendations = %v", result.ActionRecommendations)
+	}
+}
+
+func TestPayload(t *testing.T) {
+	production := Production{Method: MethodPersistence, Points: []Point{{Time: from.Add(time.Hour), Value: 100}}}
+	params := Params{BatteryParams: client.BatteryParams{BatteryCapacityWh: 1000}}
+
+	m := Payload(production, from, time.Hour, params, ReasonCircuitOpen, "", 12)
+	if id, _ := m["session_id"].(string); !strings.HasPrefix(id, "baseline-") {
+		t.Errorf("session_id = %v, want a generated baseline- id", m["session_id"])
+	}
+	ts, _ := m["timestamp"].(string)
+	if parsed, err := time.Parse(TimestampLayout, ts); err != nil || !parsed.Equal(from) {
+		t.Errorf("timestamp = %q, want from in TimestampLayout", ts)
+	}
+	recs := m["result"].(map[string]interface{})["action_recommendations"].([]interface{})
+	if !strings.HasPrefix(recs[0].(string), "ML forecaster unavailable") {
+		t.Errorf("first recommendation = %v, want the circuit-open label", recs[0])
+	}
+	info := m["baseline"].(map[string]interface{})
+	if info["reason"] != ReasonCircuitOpen || info["history_samples"] != 12.0 || info["step_seconds"] != 3600.0 || len(info["points"].([]interface{})) != 1 {
+		t.Errorf("baseline = %v", info)
+	}
+
+	m = Payload(production, from, time.Hour, params, ReasonOnDemand, "given", 1)
+	if m["session_id"] != "given" || m["general_status"] != "baseline" {
+		t.Errorf("Payload() = %v", m)
+	}
+}
@@ -0,0 +1,52 @@
+package baseline
+
+import (
+	"context"
+	"fmt"
+	"solar-scope/internal/client"
+	"sort"
+	"time"
+)
+
+// maxPoints, geçmiş sorgusunda seri başına istenecek en fazla nokta
+// sayısıdır; uzun pencerelerde adım buna göre büyütülür.
+const maxPoints = 10000
+
+// Forecaster, üretim geçmişini VictoriaMetrics'ten alıp baseline tahmini
+// üretir.
+type Forecaster struct {
+	Step time.Duration
+}
+
+// Run, p.MetricName'in son p.TrainDays günlük geçmişinden tahmin üretir.
+// Birden fazla seri eşleşirse her zaman noktasında toplanır.
+func (f Forecaster) Run(ctx context.Context, pc *client.PrometheusClient, p Params, reason, sessionID string) (map[string]interface{}, error) {
+	window := time.Duration(p.TrainDays) * 24 * time.Hour
+	step := max(f.Step, time.Minute)
+	if minStep := (window / maxPoints).Round(time.Minute); step < minStep {
+		step = minStep
+	}
+	now := time.Now().Truncate(step)
+
+	matrix, err := pc.QueryRange(ctx, p.MetricName, now.Add(-window), now, step)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query production history: %w", err)
+	}
+	sums := map[int64]float64{}
+	for _, stream := range matrix {
+		for _, sample := range stream.Values {
+			sums[sample.Timestamp.Unix()] += float64(sample.Value)
+		}
+	}
+	history := make([]Point, 0, len(sums))
+	for ts, v := range sums {
+		history = append(history, Point{Time: time.Unix(ts, 0), Value: v})
+	}
+	sort.Slice(history, func(i, j int) bool { return history[i].Time.Before(history[j].Time) })
+
+	production, err := Predict(history, now, step, p.Method)
+	if err != nil {
+		return nil, err
+	}
+	return Payload(production, now, step, p, reason, sessionID, len(history)), nil
+}
@@ -0,0 +1,252 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"solar-scope/internal/metrics"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Yük dağıtım stratejileri.
+const (
+	RoundRobin    = "round_robin"
+	LeastInFlight = "least_in_flight"
+)
+
+// Balancing, birden fazla SolarForecaster backend'i arasında yükün nasıl
+// dağıtılacağını belirler.
+type Balancing struct {
+	// Strategy, RoundRobin veya LeastInFlight'tır; boşsa RoundRobin.
+	Strategy string
+	// HealthInterval, backend'lerin yoklanma aralığıdır; 0 ise yoklanmaz.
+	HealthInterval time.Duration
+}
+
+// backend, tek bir SolarForecaster kopyasıdır. Her backend'in kendi devre
+// kesicisi vardır; biri çöktüğünde diğerleri kullanılmaya devam eder.
+type backend struct {
+	url      string
+	breaker  *breaker
+	inFlight atomic.Int64
+	healthy  atomic.Bool
+}
+
+func newBackend(url string, resilience Resilience) *backend {
+	b := &backend{url: url}
+	b.healthy.Store(true) // İlk yoklamaya kadar sağlıklı varsayılır
+	b.breaker = newBreaker(resilience.BreakerThreshold, resilience.BreakerCooldown, func(state BreakerState) {
+		metrics.SetForecasterCircuitState(url, int(state))
+	})
+	metrics.SetForecasterBackendUp(url, true)
+	return b
+}
+
+func (b *backend) setHealthy(healthy bool) {
+	if b.healthy.Swap(healthy) != healthy {
+		slog.Info("forecaster backend health changed", "backend", b.url, "healthy", healthy)
+	}
+	metrics.SetForecasterBackendUp(b.url, healthy)
+}
+
+// candidates, denenecek backend'leri tercih sırasıyla döner: sağlıklı
+// görünenler stratejiye göre sıralanır, yoklaması başarısız olanlar sona
+// eklenir (yoklama sonucu eskimiş olabilir).
+func (sfc *SolarForecasterClient) candidates() []*backend {
+	var healthy, unhealthy []*backend
+	for _, b := range sfc.backends {
+		if b.healthy.Load() {
+			healthy = append(healthy, b)
+		} else {
+			unhealthy = append(unhealthy, b)
+		}
+	}
+
+	switch {
+	case len(healthy) < 2:
+	case sfc.balancing.Strategy == LeastInFlight:
+		sort.SliceStable(healthy, func(i, j int) bool {
+			return healthy[i].inFlight.Load() < healthy[j].inFlight.Load()
+		})
+	default:
+		start := int(sfc.next.Add(1)-1) % len(healthy)
+		healthy = slices.Concat(healthy[start:], healthy[:start])
+	}
+	return append(healthy, unhealthy...)
+}
+
+// acquire, isteğin gönderileceği backend'i seçer ve devre kesicisinden izin
+// alır. Bu çağrıda denenmemiş backend'ler önceliklidir. Session'a bağlı
+// çağrılar yalnızca session'ın sahibi olan backend'e gider.
+func (sfc *SolarForecasterClient) acquire(ctx context.Context, sessionID string, tried []*backend) (*backend, error) {
+	if sessionID != "" {
+		owner, err := sfc.sessionOwner(ctx, sessionID)
+		if err != nil {
+			return nil, err
+		}
+		if !owner.breaker.Allow() {
+			return nil, &UpstreamError{Err: ErrCircuitOpen}
+		}
+		return owner, nil
+	}
+
+	candidates := sfc.candidates()
+	for _, b := range candidates {
+		if !slices.Contains(tried, b) && b.breaker.Allow() {
+			return b, nil
+		}
+	}
+	for _, b := range candidates {
+		if slices.Contains(tried, b) && b.breaker.Allow() {
+			return b, nil
+		}
+	}
+	return nil, &UpstreamError{Err: ErrCircuitOpen}
+}
+
+// sessionOwner, session'ı oluşturan backend'i döner. Tek backend varsa o
+// kullanılır; aksi halde bilinmeyen session'lar (örn. yeniden başlatma sonrası)
+// backend'lerin session listelerinden bulunur.
+func (sfc *SolarForecasterClient) sessionOwner(ctx context.Context, sessionID string) (*backend, error) {
+	if len(sfc.backends) == 1 {
+		return sfc.backends[0], nil
+	}
+	if owner, ok := sfc.sessions.Load(sessionID); ok {
+		return owner.(*backend), nil
+	}
+
+	sfc.discoverSessions(ctx)
+	if owner, ok := sfc.sessions.Load(sessionID); ok {
+		return owner.(*backend), nil
+	}
+	return nil, &UpstreamError{
+		Status:  http.StatusNotFound,
+		Message: "Session not found on any forecaster backend",
+	}
+}
+
+// discoverSessions, tüm backend'lerin session listelerini alıp sahiplik
+// tablosunu günceller.
+func (sfc *SolarForecasterClient) discoverSessions(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, b := range sfc.backends {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := sfc.backendRequest(ctx, b, EndpointSessions, "GET", "/sessions")
+			if err != nil {
+				slog.WarnContext(ctx, "error listing sessions on forecaster backend", "backend", b.url, "error", err)
+				return
+			}
+			sfc.rememberSessions(b, result)
+		}()
+	}
+	wg.Wait()
+}
+
+// rememberSessions, bir /sessions yanıtındaki session'ları backend'e bağlar.
+func (sfc *SolarForecasterClient) rememberSessions(b *backend, result map[string]interface{}) {
+	for _, id := range SessionIDs(result) {
+		sfc.sessions.Store(id, b)
+	}
+}
+
+// healthLoop, backend'leri düzenli olarak yoklar; Close çağrılınca durur.
+func (sfc *SolarForecasterClient) healthLoop(interval time.Duration) {
+	defer close(sfc.healthDone)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sfc.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			var wg sync.WaitGroup
+			for _, b := range sfc.backends {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					b.setHealthy(sfc.pingBackend(ctx, b) == nil)
+				}()
+			}
+			wg.Wait()
+			cancel()
+		}
+	}
+}
+
+// pingBackend, backend'e ulaşılabildiğini doğrular. Sunucu hatası dışındaki
+// her HTTP yanıtı servisin ayakta olduğunu gösterir.
+func (sfc *SolarForecasterClient) pingBackend(ctx context.Context, b *backend) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", b.url+"/", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	setRequestID(req)
+	resp, err := sfc.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// dialFailed, isteğin backend'e hiç ulaşmadığını söyler. Bu durumda /run gibi
+// yeniden denenmeyen çağrılar da başka bir backend'e güvenle aktarılabilir.
+func dialFailed(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// mergeSessions, backend'lerin /sessions yanıtlarını tek bir yanıtta
+// birleştirir. Listeler uç uca eklenir, ID'ye göre anahtarlanmış nesneler
+// birleştirilir ve count alanı toplam sayıya göre güncellenir.
+func mergeSessions(results []map[string]interface{}) map[string]interface{} {
+	merged := map[string]interface{}{}
+	for _, result := range results {
+		for key, value := range result {
+			if _, ok := merged[key]; !ok {
+				merged[key] = value
+				continue
+			}
+			if !slices.Contains(sessionListKeys, key) {
+				continue
+			}
+			switch sessions := value.(type) {
+			case []interface{}:
+				if existing, ok := merged[key].([]interface{}); ok {
+					merged[key] = append(slices.Clip(existing), sessions...)
+				}
+			case map[string]interface{}:
+				if existing, ok := merged[key].(map[string]interface{}); ok {
+					combined := make(map[string]interface{}, len(existing)+len(sessions))
+					for id, item := range existing {
+						combined[id] = item
+					}
+					for id, item := range sessions {
+						combined[id] = item
+					}
+					merged[key] = combined
+				}
+			}
+		}
+	}
+	if _, ok := merged["count"]; ok {
+		merged["count"] = len(SessionIDs(merged))
+	}
+	return merged
+}
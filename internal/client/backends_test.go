@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeForecaster, çağrıları sayan ve bildiği session'ları listeleyen bir
+// SolarForecaster taklididir.
+type fakeForecaster struct {
+	*httptest.Server
+	name string
+
+	mu       sync.Mutex
+	hits     map[string]int
+	sessions []string
+}
+
+func newFakeForecaster(t *testing.T, name string, sessions ...string) *fakeForecaster {
+	f := &fakeForecaster{name: name, hits: map[string]int{}, sessions: sessions}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		path := r.URL.Path
+		f.hits[path]++
+		var body interface{} = map[string]interface{}{"backend": name}
+		switch {
+		case path == "/upload-env":
+			id := name + "-session"
+			f.sessions = append(f.sessions, id)
+			body = map[string]interface{}{"session_id": id}
+		case path == "/sessions":
+			body = map[string]interface{}{"sessions": f.sessions, "count": len(f.sessions)}
+		case strings.HasPrefix(path, "/run-with-env/"):
+			if !slices.Contains(f.sessions, strings.TrimPrefix(path, "/run-with-env/")) {
+				http.Error(w, `{"detail": "session not found"}`, http.StatusNotFound)
+				return
+			}
+		}
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeForecaster) count(path string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.hits[path]
+}
+
+func newTestClient(t *testing.T, balancing Balancing, urls ...string) *SolarForecasterClient {
+	t.Helper()
+	sfc, err := NewSolarForecasterClient(urls, balancing, Resilience{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(sfc.Close)
+	return sfc
+}
+
+func TestRoundRobin(t *testing.T) {
+	a, b := newFakeForecaster(t, "a"), newFakeForecaster(t, "b")
+	sfc := newTestClient(t, Balancing{}, a.URL, b.URL+"/")
+	for range 4 {
+		if _, err := sfc.GetSampleEnv(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if a.count("/sample-env") != 2 || b.count("/sample-env") != 2 {
+		t.Errorf("calls = %d and %d, want 2 and 2", a.count("/sample-env"), b.count("/sample-env"))
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	sfc := newTestClient(t, Balancing{Strategy: LeastInFlight}, "http://a", "http://b", "http://c")
+	a, b, c := sfc.backends[0], sfc.backends[1], sfc.backends[2]
+	a.inFlight.Store(3)
+	b.inFlight.Store(1)
+	c.setHealthy(false)
+	if got, want := sfc.candidates(), []*backend{b, a, c}; !reflect.DeepEqual(got, want) {
+		t.Errorf("candidates() = %v, want least in flight first and unhealthy last", urls(got))
+	}
+
+	if _, err := NewSolarForecasterClient([]string{"http://a"}, Balancing{Strategy: "random"}, Resilience{}); err == nil {
+		t.Error("NewSolarForecasterClient() accepted an unknown strategy")
+	}
+	if _, err := NewSolarForecasterClient(nil, Balancing{}, Resilience{}); err == nil {
+		t.Error("NewSolarForecasterClient() accepted no backends")
+	}
+}
+
+func urls(backends []*backend) []string {
+	var out []string
+	for _, b := range backends {
+		out = append(out, b.url)
+	}
+	return out
+}
+
+func TestFailoverOnDialError(t *testing.T) {
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+	live := newFakeForecaster(t, "live")
+	sfc := newTestClient(t, Balancing{}, dead.URL, live.URL)
+
+	// /run yeniden denenmez ama hiç ulaşılamayan backend'den aktarılır
+	for range 2 {
+		if _, err := sfc.RunForecast(context.Background(), RunRequest{}); err != nil {
+			t.Fatalf("RunForecast() error = %v", err)
+		}
+	}
+	if live.count("/run") != 2 || sfc.backends[0].healthy.Load() {
+		t.Errorf("live backend got %d calls, want 2 and the dead one marked unhealthy", live.count("/run"))
+	}
+}
+
+func TestSessionAffinity(t *testing.T) {
+	a, b := newFakeForecaster(t, "a"), newFakeForecaster(t, "b", "restored")
+	sfc := newTestClient(t, Balancing{}, a.URL, b.URL)
+
+	path := filepath.Join(t.TempDir(), "site.env")
+	if err := os.WriteFile(path, []byte("TRAIN_DAYS=7\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	uploaded, err := sfc.UploadEnvFile(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := uploaded["session_id"].(string)
+	owner := map[string]*fakeForecaster{"a-session": a, "b-session": b}[id]
+	for range 3 {
+		if _, err := sfc.RunWithEnv(context.Background(), id, nil); err != nil {
+			t.Fatalf("RunWithEnv() error = %v", err)
+		}
+	}
+	if owner.count("/run-with-env/"+id) != 3 || sfc.SessionBackend(id) != owner.URL {
+		t.Errorf("session %s calls on its backend = %d, want 3", id, owner.count("/run-with-env/"+id))
+	}
+
+	// Bilinmeyen session backend'lerin listelerinden bulunur
+	if _, err := sfc.RunWithEnv(context.Background(), "restored", nil); err != nil || b.count("/run-with-env/restored") != 1 {
+		t.Errorf("RunWithEnv(restored) = %v, want it sent to the backend listing it", err)
+	}
+	_, err = sfc.RunWithEnv(context.Background(), "missing", nil)
+	var ue *UpstreamError
+	if !errors.As(err, &ue) || ue.Status != http.StatusNotFound {
+		t.Errorf("RunWithEnv(missing) = %v, want 404", err)
+	}
+}
+
+func TestGetSessionsMerges(t *testing.T) {
+	a, b := newFakeForecaster(t, "a", "s1"), newFakeForecaster(t, "b", "s2", "s3")
+	sfc := newTestClient(t, Balancing{}, a.URL, b.URL)
+	result, err := sfc.GetSessions(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := SessionIDs(result); len(ids) != 3 || result["count"] != 3 {
+		t.Errorf("GetSessions() = %v, want three sessions", result)
+	}
+	if sfc.SessionBackend("s3") != b.URL {
+		t.Errorf("SessionBackend(s3) = %q, want %q", sfc.SessionBackend("s3"), b.URL)
+	}
+}
+
+func TestMergeSessions(t *testing.T) {
+	merged := mergeSessions([]map[string]interface{}{
+		{"sessions": map[string]interface{}{"s1": 1}, "count": 1, "status": "ok"},
+		{"sessions": map[string]interface{}{"s2": 2}, "count": 1, "status": "other"},
+	})
+	want := map[string]interface{}{"sessions": map[string]interface{}{"s1": 1, "s2": 2}, "count": 2, "status": "ok"}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("mergeSessions() = %v, want %v", merged, want)
+	}
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen, devre kesici açıkken yapılan çağrılarda döner; istek
+// SolarForecaster'a gönderilmez.
+var ErrCircuitOpen = errors.New("forecaster circuit breaker is open")
+
+// BreakerState, devre kesicinin durumudur.
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerHalfOpen
+	BreakerOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerHalfOpen:
+		return "half_open"
+	case BreakerOpen:
+		return "open"
+	default:
+		return "closed"
+	}
+}
+
+// breaker, art arda gelen upstream hatalarında devreyi açar. Bekleme süresi
+// dolunca tek bir deneme isteğine izin verir (half-open); deneme başarılıysa
+// devre kapanır, başarısızsa yeniden açılır.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+	onChange  func(BreakerState)
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newBreaker(threshold int, cooldown time.Duration, onChange func(BreakerState)) *breaker {
+	b := &breaker{threshold: threshold, cooldown: cooldown, onChange: onChange}
+	if onChange != nil {
+		onChange(BreakerClosed)
+	}
+	return b
+}
+
+// Allow, bir isteğin gönderilip gönderilemeyeceğini söyler. threshold 0 ise
+// devre kesici devre dışıdır.
+func (b *breaker) Allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.setState(BreakerHalfOpen)
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		// Deneme isteği sürerken diğer istekler beklemeden reddedilir
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Record, Allow ile izin verilen bir isteğin sonucunu işler.
+func (b *breaker) Record(failed bool) {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if !failed {
+		b.failures = 0
+		if b.state != BreakerClosed {
+			b.setState(BreakerClosed)
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.openedAt = time.Now()
+		b.setState(BreakerOpen)
+	}
+}
+
+// Release, sonucu değerlendirilmeyen bir isteğin (örn. çağıranın iptal
+// ettiği) deneme hakkını geri verir.
+func (b *breaker) Release() {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	b.probing = false
+	b.mu.Unlock()
+}
+
+// State, devre kesicinin anlık durumunu döner.
+func (b *breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *breaker) setState(state BreakerState) {
+	b.state = state
+	if b.onChange != nil {
+		b.onChange(state)
+	}
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	var states []BreakerState
+	b := newBreaker(2, time.Minute, func(s BreakerState) { states = append(states, s) })
+
+	// Eşiğin altındaki hata devreyi açmaz, başarı sayacı sıfırlar
+	b.Allow()
+	b.Record(true)
+	b.Allow()
+	b.Record(false)
+	b.Allow()
+	b.Record(true)
+	if b.State() != BreakerClosed {
+		t.Fatalf("state = %s after non-consecutive failures, want closed", b.State())
+	}
+	b.Allow()
+	b.Record(true)
+	if b.State() != BreakerOpen || b.Allow() {
+		t.Fatalf("state = %s after consecutive failures, want open and rejecting", b.State())
+	}
+
+	// Bekleme süresi dolunca yalnızca tek bir deneme isteğine izin verilir
+	b.openedAt = time.Now().Add(-time.Minute)
+	if !b.Allow() || b.State() != BreakerHalfOpen {
+		t.Fatalf("state = %s after cooldown, want half_open allowing a probe", b.State())
+	}
+	if b.Allow() {
+		t.Fatal("second request allowed while the probe is in flight")
+	}
+	b.Record(true)
+	if b.State() != BreakerOpen {
+		t.Fatalf("state = %s after a failed probe, want open", b.State())
+	}
+
+	b.openedAt = time.Now().Add(-time.Minute)
+	b.Allow()
+	b.Record(false)
+	if b.State() != BreakerClosed || !b.Allow() {
+		t.Fatalf("state = %s after a successful probe, want closed", b.State())
+	}
+
+	want := []BreakerState{BreakerClosed, BreakerOpen, BreakerHalfOpen, BreakerOpen, BreakerHalfOpen, BreakerClosed}
+	if !slices.Equal(states, want) {
+		t.Errorf("state changes = %v, want %v", states, want)
+	}
+}
+
+func TestBreakerRelease(t *testing.T) {
+	b := newBreaker(1, time.Minute, nil)
+	b.Allow()
+	b.Record(true)
+	b.openedAt = time.Now().Add(-time.Minute)
+	if !b.Allow() {
+		t.Fatal("probe not allowed after cooldown")
+	}
+	// Çağıranın iptal ettiği deneme devreyi açmaz, hakkı geri verir
+	b.Release()
+	if b.State() != BreakerHalfOpen || !b.Allow() {
+		t.Errorf("state = %s after release, want half_open allowing a new probe", b.State())
+	}
+}
+
+func TestBreakerDisabled(t *testing.T) {
+	b := newBreaker(0, time.Minute, nil)
+	for range 10 {
+		b.Allow()
+		b.Record(true)
+	}
+	if b.State() != BreakerClosed || !b.Allow() {
+		t.Errorf("disabled breaker state = %s, want closed", b.State())
+	}
+}
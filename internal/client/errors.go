@@ -0,0 +1,94 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+// maxErrorBody, hata yanıtından okunacak en fazla bayt sayısıdır.
+const maxErrorBody = 64 << 10
+
+// maxErrorMessage, istemciye iletilecek upstream mesajının rune sınırıdır.
+const maxErrorMessage = 300
+
+// ErrInvalidResponse, başarılı durum kodlu bir yanıtın gövdesi çözülemediğinde
+// UpstreamError içinde döner.
+var ErrInvalidResponse = errors.New("forecaster returned an invalid response body")
+
+// UpstreamError, SolarForecaster'a ulaşılamadığında, 200 dışı bir yanıt
+// döndüğünde veya yanıt gövdesi çözülemediğinde oluşur. Status 0 ise istek
+// sunucuya hiç ulaşmamıştır.
+type UpstreamError struct {
+	Status  int
+	Message string
+	Details interface{}
+	Err     error
+}
+
+func (e *UpstreamError) Error() string {
+	if errors.Is(e.Err, ErrCircuitOpen) || errors.Is(e.Err, ErrInvalidResponse) {
+		return e.Err.Error()
+	}
+	if e.Status == 0 {
+		return fmt.Sprintf("forecaster unreachable: %v", e.Err)
+	}
+	if e.Message != "" {
+		return fmt.Sprintf("forecaster returned status %d: %s", e.Status, e.Message)
+	}
+	return fmt.Sprintf("forecaster returned status %d", e.Status)
+}
+
+func (e *UpstreamError) Unwrap() error {
+	return e.Err
+}
+
+// newUpstreamError, 200 dışı yanıtın gövdesinden mesajı ve ayrıntıları çıkarır.
+// FastAPI tarzı {"detail": ...} gövdeleri ile message/error alanları tanınır;
+// JSON olmayan gövdeler düz metin olarak alınır.
+func newUpstreamError(resp *http.Response) *UpstreamError {
+	ue := &UpstreamError{Status: resp.StatusCode}
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if err != nil || len(raw) == 0 {
+		return ue
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		ue.Message = sanitizeMessage(string(raw))
+		return ue
+	}
+	for _, key := range []string{"detail", "message", "error"} {
+		value, ok := body[key]
+		if !ok {
+			continue
+		}
+		if text, ok := value.(string); ok {
+			ue.Message = sanitizeMessage(text)
+		} else {
+			// Örn. FastAPI doğrulama hataları bir liste olarak gelir
+			ue.Details = value
+		}
+		break
+	}
+	return ue
+}
+
+// sanitizeMessage, kontrol karakterlerini temizler ve mesajı kısaltır.
+func sanitizeMessage(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, s)
+	s = strings.Join(strings.Fields(s), " ")
+	if runes := []rune(s); len(runes) > maxErrorMessage {
+		s = string(runes[:maxErrorMessage]) + "…"
+	}
+	return s
+}
@@ -3,44 +3,211 @@ package client
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"solar-scope/internal/logging"
+	"solar-scope/internal/metrics"
+	"solar-scope/internal/tracing"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/api"
 	prometheusV1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // PrometheusClient is a client for interacting with Prometheus API.
 type PrometheusClient struct {
-	api prometheusV1.API
+	api     prometheusV1.API
+	address string
+	timeout time.Duration // sorgu başına zaman aşımı; 0 ise yalnızca ctx geçerlidir
+	tenants sync.Map      // "label=value" -> *PrometheusClient
 }
 
-func NewPrometheusClient(prometheusURL string) (*PrometheusClient, error) {
+func NewPrometheusClient(prometheusURL string, timeout time.Duration) (*PrometheusClient, error) {
+	return newPrometheusClient(prometheusURL, timeout, baseRoundTripper())
+}
+
+func newPrometheusClient(prometheusURL string, timeout time.Duration, rt http.RoundTripper) (*PrometheusClient, error) {
 	client, err := api.NewClient(api.Config{
-		Address: prometheusURL,
+		Address:      prometheusURL,
+		RoundTripper: rt,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Prometheus client: %w", err)
 	}
 
 	return &PrometheusClient{
-		api: prometheusV1.NewAPI(client),
+		api:     prometheusV1.NewAPI(client),
+		address: prometheusURL,
+		timeout: timeout,
 	}, nil
 }
 
+// CheckPrometheusURL, adreste Prometheus uyumlu bir sunucunun yanıt verdiğini
+// doğrular. Süre sınırı ctx ile verilir.
+func CheckPrometheusURL(ctx context.Context, prometheusURL string) error {
+	pc, err := NewPrometheusClient(prometheusURL, 0)
+	if err != nil {
+		return err
+	}
+	return pc.Ping(ctx)
+}
+
+// PrometheusURLAllowed, adresin izin listesindeki taban adreslerden birinin
+// altında olup olmadığını söyler. Şema ve host (port dahil) birebir, yol ise
+// önek olarak karşılaştırılır; kullanıcı bilgisi içeren adresler reddedilir.
+func PrometheusURLAllowed(prometheusURL string, allowlist []string) bool {
+	u, err := url.Parse(prometheusURL)
+	if err != nil || u.User != nil || u.Host == "" {
+		return false
+	}
+	for _, entry := range allowlist {
+		base, err := url.Parse(entry)
+		if err != nil || base.Host == "" {
+			continue
+		}
+		if !strings.EqualFold(u.Scheme, base.Scheme) || !strings.EqualFold(u.Host, base.Host) {
+			continue
+		}
+		prefix := strings.TrimSuffix(base.Path, "/")
+		if path := strings.TrimSuffix(u.Path, "/"); path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// ForTenant, sorgularına VictoriaMetrics'in extra_label parametresiyle
+// label=value filtresini zorunlu olarak ekleyen bir istemci döner. Filtre
+// sunucu tarafında uygulandığı için sorgu metniyle aşılamaz.
+func (pc *PrometheusClient) ForTenant(label, value string) (*PrometheusClient, error) {
+	if label == "" || value == "" {
+		return nil, fmt.Errorf("tenant label filter is not configured")
+	}
+	key := label + "=" + value
+	if cached, ok := pc.tenants.Load(key); ok {
+		return cached.(*PrometheusClient), nil
+	}
+
+	scoped, err := newPrometheusClient(pc.address, pc.timeout, &extraLabelRoundTripper{
+		next:  baseRoundTripper(),
+		label: key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := pc.tenants.LoadOrStore(key, scoped)
+	return actual.(*PrometheusClient), nil
+}
+
+// extraLabelRoundTripper, her isteğin URL'ine extra_label parametresini ekler.
+type extraLabelRoundTripper struct {
+	next  http.RoundTripper
+	label string
+}
+
+func (rt *extraLabelRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	query := req.URL.Query()
+	query.Set("extra_label", rt.label)
+	req.URL.RawQuery = query.Encode()
+	return rt.next.RoundTrip(req)
+}
+
+// baseRoundTripper, istek kimliğini ve trace başlıklarını ileten taşıyıcıyı döner.
+func baseRoundTripper() http.RoundTripper {
+	return &requestIDRoundTripper{next: otelhttp.NewTransport(api.DefaultRoundTripper)}
+}
+
+// requestIDRoundTripper, context'teki istek kimliğini VictoriaMetrics'e iletir.
+type requestIDRoundTripper struct {
+	next http.RoundTripper
+}
+
+func (rt *requestIDRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if id := logging.RequestID(req.Context()); id != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(logging.HeaderRequestID, id)
+	}
+	return rt.next.RoundTrip(req)
+}
+
 // Query anlık bi PromQL sorgusu çalıştırır ve sonucu döner.
-func (pc *PrometheusClient) Query(query string) (model.Value, error) {
-	// 5 saniyelik bir zaman aşımı ile sorguyu çalıştır
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+func (pc *PrometheusClient) Query(ctx context.Context, query string) (result model.Value, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "VictoriaMetrics query",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("metric.query", query)),
+	)
+	start := time.Now()
+	defer func() {
+		metrics.ObserveVMQuery(time.Since(start), err)
+		endSpan(span, err)
+	}()
+
+	// Yapılandırılan zaman aşımı ile sorguyu çalıştır; ctx iptal edilirse
+	// (örn. istemci bağlantıyı kapattığında) sorgu da iptal olur
+	if pc.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, pc.timeout)
+		defer cancel()
+	}
 	// Sorguyu çalıştır
 	result, warnings, err := pc.api.Query(ctx, query, time.Now())
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
 	if len(warnings) > 0 {
-		fmt.Printf("Warnings: %v\n", warnings)
+		slog.WarnContext(ctx, "query returned warnings", "query", query, "warnings", warnings)
 	}
 
 	return result, nil
 }
+
+// QueryRange, [start, end] aralığında step adımlarıyla bir PromQL sorgusu
+// çalıştırır ve seri matrisini döner.
+func (pc *PrometheusClient) QueryRange(ctx context.Context, query string, start, end time.Time, step time.Duration) (result model.Matrix, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "VictoriaMetrics range query",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("metric.query", query),
+			attribute.String("metric.step", step.String()),
+		),
+	)
+	began := time.Now()
+	defer func() {
+		metrics.ObserveVMQuery(time.Since(began), err)
+		endSpan(span, err)
+	}()
+
+	if pc.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, pc.timeout)
+		defer cancel()
+	}
+	value, warnings, err := pc.api.QueryRange(ctx, query, prometheusV1.Range{Start: start, End: end, Step: step})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute range query: %w", err)
+	}
+	if len(warnings) > 0 {
+		slog.WarnContext(ctx, "range query returned warnings", "query", query, "warnings", warnings)
+	}
+	matrix, ok := value.(model.Matrix)
+	if !ok {
+		return nil, fmt.Errorf("unexpected range query result type %s", value.Type())
+	}
+	return matrix, nil
+}
+
+// Ping, buildinfo uç noktasını sorgulayarak sunucuya ulaşılabildiğini doğrular.
+func (pc *PrometheusClient) Ping(ctx context.Context) error {
+	if _, err := pc.api.Buildinfo(ctx); err != nil {
+		return fmt.Errorf("failed to query buildinfo: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// SolarForecaster uç noktalarının yeniden deneme ve metrik ayarlarında
+// kullanılan adları.
+const (
+	EndpointRun           = "run"
+	EndpointUploadEnv     = "upload-env"
+	EndpointRunWithEnv    = "run-with-env"
+	EndpointSessions      = "sessions"
+	EndpointDeleteSession = "delete-session"
+	EndpointSampleEnv     = "sample-env"
+)
+
+// Endpoints, yapılandırılabilen tüm uç nokta adlarıdır.
+var Endpoints = []string{
+	EndpointRun, EndpointUploadEnv, EndpointRunWithEnv,
+	EndpointSessions, EndpointDeleteSession, EndpointSampleEnv,
+}
+
+// Resilience, SolarForecasterClient'ın yeniden deneme ve devre kesici
+// ayarlarıdır.
+type Resilience struct {
+	// Attempts, uç nokta adına göre toplam deneme sayısıdır. Tanımsız uç
+	// noktalar bir kez denenir; /run gibi pahalı çağrılar bu yüzden varsayılan
+	// olarak yeniden denenmez.
+	Attempts map[string]int
+	// BaseDelay ve MaxDelay, jitter'lı üstel geri çekilmenin sınırlarıdır.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Timeout, bir denemenin varsayılan zaman aşımıdır; Timeouts uç nokta
+	// adına göre bunu geçersiz kılar. İkisi de 0 ise yalnızca çağıranın ctx'i
+	// geçerlidir.
+	Timeout  time.Duration
+	Timeouts map[string]time.Duration
+	// BreakerThreshold art arda kaç hatada devrenin açılacağını, BreakerCooldown
+	// açık devrenin ne kadar sonra yeniden deneneceğini belirler. Eşik 0 ise
+	// devre kesici kapalıdır.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// attempts, uç nokta için toplam deneme sayısını döner.
+func (r Resilience) attempts(name string) int {
+	if n := r.Attempts[name]; n > 1 {
+		return n
+	}
+	return 1
+}
+
+// timeout, uç noktanın deneme başına zaman aşımını döner.
+func (r Resilience) timeout(name string) time.Duration {
+	if d, ok := r.Timeouts[name]; ok && d > 0 {
+		return d
+	}
+	return r.Timeout
+}
+
+// backoff, attempt'inci denemeden sonra beklenecek süreyi "full jitter"
+// yöntemiyle hesaplar: [0, min(MaxDelay, BaseDelay*2^(attempt-1))).
+func (r Resilience) backoff(attempt int) time.Duration {
+	if r.BaseDelay <= 0 {
+		return 0
+	}
+	ceiling := r.BaseDelay << (attempt - 1)
+	if r.MaxDelay > 0 && (ceiling <= 0 || ceiling > r.MaxDelay) {
+		ceiling = r.MaxDelay
+	}
+	if ceiling <= 0 {
+		ceiling = r.BaseDelay
+	}
+	return rand.N(ceiling) + 1
+}
+
+// retryable, hatanın geçici olup olmadığını söyler: bağlantı hataları ile
+// 429, 502, 503 ve 504 yanıtları yeniden denenir.
+func retryable(err error) bool {
+	var ue *UpstreamError
+	if !errors.As(err, &ue) || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	switch ue.Status {
+	case 0, http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// upstreamFailure, hatanın devre kesici açısından SolarForecaster'ın
+// arızası sayılıp sayılmayacağını söyler. 4xx yanıtlar servisin ayakta
+// olduğunu gösterir; çözülemeyen 200 yanıtları ise arızadır.
+func upstreamFailure(err error) bool {
+	var ue *UpstreamError
+	if !errors.As(err, &ue) {
+		return false
+	}
+	return ue.Status == 0 || ue.Status >= http.StatusInternalServerError || errors.Is(ue.Err, ErrInvalidResponse)
+}
+
+// sleep, d kadar veya ctx iptal edilene kadar bekler.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
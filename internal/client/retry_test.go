@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	r := Resilience{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt, ceiling := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 4: 800 * time.Millisecond, 5: time.Second, 70: time.Second} {
+		for range 100 {
+			if d := r.backoff(attempt); d <= 0 || d > ceiling {
+				t.Fatalf("backoff(%d) = %s, want in (0, %s]", attempt, d, ceiling)
+			}
+		}
+	}
+	if d := (Resilience{}).backoff(3); d != 0 {
+		t.Errorf("backoff without a base delay = %s, want 0", d)
+	}
+}
+
+func TestAttemptsAndTimeouts(t *testing.T) {
+	r := Resilience{
+		Attempts: map[string]int{EndpointSessions: 3, EndpointRun: 0},
+		Timeout:  time.Second,
+		Timeouts: map[string]time.Duration{EndpointRun: time.Minute, EndpointSampleEnv: 0},
+	}
+	if r.attempts(EndpointSessions) != 3 || r.attempts(EndpointRun) != 1 || r.attempts(EndpointSampleEnv) != 1 {
+		t.Error("attempts() should default to one try")
+	}
+	if r.timeout(EndpointRun) != time.Minute || r.timeout(EndpointSampleEnv) != time.Second {
+		t.Error("timeout() should fall back to the default timeout")
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		err       error
+		retryable bool
+		failure   bool
+	}{
+		{&UpstreamError{Err: errors.New("connection refused")}, true, true},
+		{&UpstreamError{Status: http.StatusServiceUnavailable}, true, true},
+		{&UpstreamError{Status: http.StatusTooManyRequests}, true, false},
+		{&UpstreamError{Status: http.StatusInternalServerError}, false, true},
+		{&UpstreamError{Status: http.StatusBadRequest}, false, false},
+		{&UpstreamError{Err: ErrCircuitOpen}, false, true},
+		{&UpstreamError{Status: http.StatusOK, Err: ErrInvalidResponse}, false, true},
+		{errors.New("failed to marshal request data"), false, false},
+	}
+	for _, tt := range tests {
+		if got := retryable(tt.err); got != tt.retryable {
+			t.Errorf("retryable(%v) = %v, want %v", tt.err, got, tt.retryable)
+		}
+		if got := upstreamFailure(tt.err); got != tt.failure {
+			t.Errorf("upstreamFailure(%v) = %v, want %v", tt.err, got, tt.failure)
+		}
+	}
+}
+
+// flakyServer, ilk failures isteğe 503, sonrakilere 200 döner.
+func flakyServer(t *testing.T, failures int32) (*httptest.Server, *atomic.Int32) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) <= failures {
+			http.Error(w, `{"detail": "busy"}`, http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"status": "ok"}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func TestRetries(t *testing.T) {
+	srv, hits := flakyServer(t, 2)
+	sfc, err := NewSolarForecasterClient([]string{srv.URL}, Balancing{}, Resilience{
+		Attempts:  map[string]int{EndpointSampleEnv: 3},
+		BaseDelay: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sfc.Close()
+
+	if _, err := sfc.GetSampleEnv(context.Background()); err != nil || hits.Load() != 3 {
+		t.Errorf("GetSampleEnv() = %v after %d calls, want success after 3", err, hits.Load())
+	}
+}
+
+func TestRunIsNotRetriedByDefault(t *testing.T) {
+	srv, hits := flakyServer(t, 1)
+	sfc, err := NewSolarForecasterClient([]string{srv.URL}, Balancing{}, Resilience{BaseDelay: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sfc.Close()
+
+	_, err = sfc.RunForecast(context.Background(), RunRequest{})
+	var ue *UpstreamError
+	if !errors.As(err, &ue) || ue.Status != http.StatusServiceUnavailable || ue.Message != "busy" || hits.Load() != 1 {
+		t.Errorf("RunForecast() = %v after %d calls, want one 503", err, hits.Load())
+	}
+}
+
+func TestBreakerStopsCalls(t *testing.T) {
+	srv, hits := flakyServer(t, 100)
+	sfc, err := NewSolarForecasterClient([]string{srv.URL}, Balancing{}, Resilience{BreakerThreshold: 2, BreakerCooldown: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sfc.Close()
+
+	for range 2 {
+		sfc.GetSampleEnv(context.Background())
+	}
+	_, err = sfc.GetSampleEnv(context.Background())
+	if !errors.Is(err, ErrCircuitOpen) || hits.Load() != 2 {
+		t.Errorf("GetSampleEnv() = %v after %d calls, want ErrCircuitOpen without a third call", err, hits.Load())
+	}
+	if sfc.BreakerState() != BreakerOpen || sfc.CheckCircuit(context.Background()) == nil {
+		t.Errorf("BreakerState() = %s, want open", sfc.BreakerState())
+	}
+}
+
+func TestInvalidResponseTripsBreaker(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Write([]byte(`<html>proxy error</html>`))
+	}))
+	t.Cleanup(srv.Close)
+	sfc, err := NewSolarForecasterClient([]string{srv.URL}, Balancing{}, Resilience{BreakerThreshold: 2, BreakerCooldown: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sfc.Close()
+
+	_, err = sfc.GetSampleEnv(context.Background())
+	var ue *UpstreamError
+	if !errors.As(err, &ue) || ue.Status != http.StatusOK || !errors.Is(err, ErrInvalidResponse) {
+		t.Fatalf("GetSampleEnv() = %v, want an invalid response UpstreamError", err)
+	}
+	sfc.GetSampleEnv(context.Background())
+	if _, err := sfc.GetSampleEnv(context.Background()); !errors.Is(err, ErrCircuitOpen) || hits.Load() != 2 {
+		t.Errorf("GetSampleEnv() = %v after %d calls, want ErrCircuitOpen without a third call", err, hits.Load())
+	}
+}
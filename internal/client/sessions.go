@@ -0,0 +1,66 @@
+package client
+
+// sessionListKeys, SolarForecaster'ın session listesini döndürebileceği alanlardır.
+var sessionListKeys = []string{"sessions", "active_sessions"}
+
+// FilterSessions, GetSessions yanıtındaki session'ları keep fonksiyonuna göre
+// süzer. Liste, session ID'lerinden veya session_id/id alanı olan nesnelerden
+// oluşabilir; ID'ye göre anahtarlanmış bir nesne de desteklenir.
+func FilterSessions(result map[string]interface{}, keep func(sessionID string) bool) map[string]interface{} {
+	filtered := make(map[string]interface{}, len(result))
+	for key, value := range result {
+		filtered[key] = value
+	}
+
+	for _, key := range sessionListKeys {
+		switch sessions := result[key].(type) {
+		case []interface{}:
+			kept := []interface{}{}
+			for _, item := range sessions {
+				if id := sessionIDOf(item); id != "" && keep(id) {
+					kept = append(kept, item)
+				}
+			}
+			filtered[key] = kept
+			if _, ok := filtered["count"]; ok {
+				filtered["count"] = len(kept)
+			}
+		case map[string]interface{}:
+			kept := map[string]interface{}{}
+			for id, item := range sessions {
+				if keep(id) {
+					kept[id] = item
+				}
+			}
+			filtered[key] = kept
+			if _, ok := filtered["count"]; ok {
+				filtered["count"] = len(kept)
+			}
+		}
+	}
+	return filtered
+}
+
+// SessionIDs, GetSessions yanıtındaki tüm session ID'lerini döner.
+func SessionIDs(result map[string]interface{}) []string {
+	var ids []string
+	FilterSessions(result, func(id string) bool {
+		ids = append(ids, id)
+		return true
+	})
+	return ids
+}
+
+func sessionIDOf(item interface{}) string {
+	switch v := item.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		for _, key := range []string{"session_id", "id"} {
+			if id, ok := v[key].(string); ok {
+				return id
+			}
+		}
+	}
+	return ""
+}
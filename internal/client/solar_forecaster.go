@@ -2,49 +2,242 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
+	"solar-scope/internal/logging"
+	"solar-scope/internal/metrics"
+	"solar-scope/internal/tracing"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // RunRequest represents the expected JSON payload for the /run endpoint.
+// Alanların kuralları env dosyalarının doğrulamasında da kullanılır.
 type RunRequest struct {
-	PrometheusURL       string  `json:"PROMETHEUS_URL"`
-	MetricName          string  `json:"METRIC_NAME"`
-	TrainDays           int     `json:"TRAIN_DAYS"`
-	BatteryCapacityWith float64 `json:"BATTERY_CAPACITY_WH"`
-	InitialSocPercent   float64 `json:"INITIAL_SOC_PERCENT"`
-	ConstantLoadW       float64 `json:"CONSTANT_LOAD_W"`
-	DetailedSummary     bool    `json:"DETAILED_SUMMARY"`
-	UseCython           bool    `json:"USE_CYTHON"`
+	PrometheusURL string `json:"PROMETHEUS_URL" validate:"required,url"`
+	MetricName    string `json:"METRIC_NAME" validate:"required,promql"`
+	BatteryParams
+	DetailedSummary bool `json:"DETAILED_SUMMARY"`
+	UseCython       bool `json:"USE_CYTHON"`
+}
+
+// BatteryParams, forecaster'ın eğitim ve batarya simülasyonu parametreleridir.
+// Sınırlar yalnızca burada tanımlanır; RunRequest ve baseline.Params bu yapıyı
+// gömer. Verim değerleri verilmezse forecaster'a gönderilmez.
+type BatteryParams struct {
+	TrainDays           int     `json:"TRAIN_DAYS" validate:"min=1,max=365"`
+	BatteryCapacityWh   float64 `json:"BATTERY_CAPACITY_WH" validate:"gt=0"`
+	InitialSocPercent   float64 `json:"INITIAL_SOC_PERCENT" validate:"min=0,max=100"`
+	ConstantLoadW       float64 `json:"CONSTANT_LOAD_W" validate:"min=0"`
+	ChargeEfficiency    float64 `json:"CHARGE_EFFICIENCY,omitempty" validate:"min=0,max=1"`
+	DischargeEfficiency float64 `json:"DISCHARGE_EFFICIENCY,omitempty" validate:"min=0,max=1"`
 }
 
 // SolarForecasterClient is a client for interacting with the Solar Forecaster service.
+// Birden fazla backend verilirse istekler aralarında dağıtılır.
 type SolarForecasterClient struct {
-	baseURL    string
+	backends   []*backend
+	balancing  Balancing
 	httpClient *http.Client
+	resilience Resilience
+
+	next     atomic.Uint64 // round-robin sırası
+	sessions sync.Map      // session ID -> *backend
+
+	stop       chan struct{}
+	healthDone chan struct{}
 }
 
-func NewSolarForecasterClient(baseURL string) *SolarForecasterClient {
-	return &SolarForecasterClient{
-		baseURL: baseURL,
+func NewSolarForecasterClient(baseURLs []string, balancing Balancing, resilience Resilience) (*SolarForecasterClient, error) {
+	if len(baseURLs) == 0 {
+		return nil, fmt.Errorf("at least one forecaster URL is required")
+	}
+	if balancing.Strategy == "" {
+		balancing.Strategy = RoundRobin
+	}
+	if balancing.Strategy != RoundRobin && balancing.Strategy != LeastInFlight {
+		return nil, fmt.Errorf("unknown balancing strategy %q", balancing.Strategy)
+	}
+	for name := range resilience.Attempts {
+		if !slices.Contains(Endpoints, name) {
+			slog.Warn("unknown forecaster endpoint in retry settings", "endpoint", name, "known", Endpoints)
+		}
+	}
+	for name := range resilience.Timeouts {
+		if !slices.Contains(Endpoints, name) {
+			slog.Warn("unknown forecaster endpoint in timeout settings", "endpoint", name, "known", Endpoints)
+		}
+	}
+
+	sfc := &SolarForecasterClient{
+		balancing: balancing,
+		// Zaman aşımları istek ctx'i üzerinden uç nokta başına uygulanır
 		httpClient: &http.Client{
-			Timeout: 60 * time.Second, // Request timeout
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
 		},
+		resilience: resilience,
+		stop:       make(chan struct{}),
+		healthDone: make(chan struct{}),
+	}
+	for _, url := range baseURLs {
+		sfc.backends = append(sfc.backends, newBackend(strings.TrimSuffix(url, "/"), resilience))
+	}
+
+	if balancing.HealthInterval > 0 {
+		go sfc.healthLoop(balancing.HealthInterval)
+	} else {
+		close(sfc.healthDone)
+	}
+	return sfc, nil
+}
+
+// Close, backend yoklamasını durdurur.
+func (sfc *SolarForecasterClient) Close() {
+	close(sfc.stop)
+	<-sfc.healthDone
+}
+
+// BreakerState, backend'ler arasındaki en iyi devre durumunu döner; yalnızca
+// tüm backend'lerin devresi açıksa BreakerOpen olur.
+func (sfc *SolarForecasterClient) BreakerState() BreakerState {
+	best := BreakerOpen
+	for _, b := range sfc.backends {
+		best = min(best, b.breaker.State())
+	}
+	return best
+}
+
+// CheckCircuit, devresi kapalı olmayan backend varsa hata döner. Hazırlık
+// kontrolünde devre kesicilerin durumunu göstermek için kullanılır.
+func (sfc *SolarForecasterClient) CheckCircuit(context.Context) error {
+	var problems []string
+	for _, b := range sfc.backends {
+		if state := b.breaker.State(); state != BreakerClosed {
+			problems = append(problems, fmt.Sprintf("%s: circuit breaker is %s", b.url, state))
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
 	}
+	return nil
 }
 
-// genericRequest, tüm istekler için ortak bir işleyici olarak çalışır.
-func (sfc *SolarForecasterClient) genericRequest(method, path string, body io.Reader, headers map[string]string) (map[string]interface{}, error) {
-	req, err := http.NewRequest(method, sfc.baseURL+path, body)
+// genericRequest, tüm istekler için ortak bir işleyici olarak çalışır. Devre
+// açıksa istek gönderilmez; geçici hatalar uç noktanın deneme sayısına kadar
+// jitter'lı üstel geri çekilmeyle, mümkünse başka bir backend'de yeniden
+// denenir. Backend'e hiç ulaşamayan istekler deneme hakkı harcamadan sıradaki
+// backend'e aktarılır. Session'a bağlı çağrılar session'ın backend'inde kalır.
+func (sfc *SolarForecasterClient) genericRequest(ctx context.Context, name, method, path string, body []byte, headers map[string]string) (result map[string]interface{}, err error) {
+	sessionID := headers["Session-ID"]
+	endpoint := method + " " + endpointTemplate(path, sessionID)
+	ctx, span := startForecasterSpan(ctx, endpoint, sessionID)
+	start := time.Now()
+	attempt := 0
+	var tried []*backend
+	defer func() {
+		span.SetAttributes(attribute.Int("forecaster.attempts", attempt))
+		if len(tried) > 0 {
+			span.SetAttributes(attribute.String("forecaster.backend", tried[len(tried)-1].url))
+		}
+		metrics.ObserveForecasterCall(endpoint, time.Since(start), err)
+		endSpan(span, err)
+	}()
+
+	maxAttempts := sfc.resilience.attempts(name)
+	for {
+		attempt++
+		b, acquireErr := sfc.acquire(ctx, sessionID, tried)
+		if acquireErr != nil {
+			if err != nil && errors.Is(acquireErr, ErrCircuitOpen) {
+				return nil, err // Son gerçek hata daha açıklayıcıdır
+			}
+			return nil, acquireErr
+		}
+		tried = append(tried, b)
+
+		result, err = sfc.doRequest(ctx, b, name, method, path, body, headers)
+		if ctx.Err() != nil {
+			// Çağıranın iptali SolarForecaster'ın arızası sayılmaz
+			b.breaker.Release()
+			return result, err
+		}
+		b.breaker.Record(upstreamFailure(err))
+
+		if err == nil {
+			sfc.track(b, name, sessionID, result)
+			return result, nil
+		}
+		if dialFailed(err) && sessionID == "" && len(tried) < len(sfc.backends) {
+			slog.WarnContext(ctx, "forecaster backend unreachable, failing over",
+				"endpoint", endpoint, "backend", b.url, "error", err)
+			b.setHealthy(false)
+			attempt--
+			continue
+		}
+		if attempt >= maxAttempts || !retryable(err) {
+			return nil, err
+		}
+		delay := sfc.resilience.backoff(attempt)
+		slog.WarnContext(ctx, "retrying forecaster call",
+			"endpoint", endpoint, "backend", b.url, "attempt", attempt, "delay", delay.String(), "error", err)
+		metrics.ForecasterRetry(endpoint)
+		if sleepErr := sleep(ctx, delay); sleepErr != nil {
+			return nil, err
+		}
+	}
+}
+
+// track, başarılı çağrılardan session sahipliğini günceller.
+func (sfc *SolarForecasterClient) track(b *backend, name, sessionID string, result map[string]interface{}) {
+	switch name {
+	case EndpointUploadEnv:
+		if id, ok := result["session_id"].(string); ok && id != "" {
+			sfc.sessions.Store(id, b)
+		}
+	case EndpointDeleteSession:
+		sfc.sessions.Delete(sessionID)
+	}
+}
+
+// doRequest, backend'e uç noktanın zaman aşımıyla tek bir HTTP isteği gönderir.
+func (sfc *SolarForecasterClient) doRequest(ctx context.Context, b *backend, name, method, path string, body []byte, headers map[string]string) (map[string]interface{}, error) {
+	if timeout := sfc.resilience.timeout(name); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	b.inFlight.Add(1)
+	metrics.AddForecasterBackendInFlight(b.url, 1)
+	defer func() {
+		b.inFlight.Add(-1)
+		metrics.AddForecasterBackendInFlight(b.url, -1)
+	}()
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, b.url+path, reader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	setRequestID(req)
 
 	for key, value := range headers {
 		req.Header.Set(key, value)
@@ -52,24 +245,25 @@ func (sfc *SolarForecasterClient) genericRequest(method, path string, body io.Re
 
 	resp, err := sfc.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return nil, &UpstreamError{Err: err}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, newUpstreamError(resp)
 	}
 
 	var result map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		// Bozuk gövde de devre kesici için bir arızadır
+		return nil, &UpstreamError{Status: resp.StatusCode, Err: fmt.Errorf("%w: %v", ErrInvalidResponse, err)}
 	}
 
 	return result, nil
 }
 
 // RunForecast, /run endpoint'ine POST isteği gönderir.
-func (sfc *SolarForecasterClient) RunForecast(reqData RunRequest) (map[string]interface{}, error) {
+func (sfc *SolarForecasterClient) RunForecast(ctx context.Context, reqData RunRequest) (map[string]interface{}, error) {
 	body, err := json.Marshal(reqData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request data: %w", err)
@@ -79,11 +273,11 @@ func (sfc *SolarForecasterClient) RunForecast(reqData RunRequest) (map[string]in
 		"Content-Type": "application/json",
 	}
 
-	return sfc.genericRequest("POST", "/run", bytes.NewReader(body), headers)
+	return sfc.genericRequest(ctx, EndpointRun, "POST", "/run", body, headers)
 }
 
 // UploadEnvFile, /upload-env endpoint'ine dosya yükleme işlemi yapar.
-func (sfc *SolarForecasterClient) UploadEnvFile(filePath string) (map[string]interface{}, error) {
+func (sfc *SolarForecasterClient) UploadEnvFile(ctx context.Context, filePath string) (map[string]interface{}, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open env file: %w", err)
@@ -106,34 +300,16 @@ func (sfc *SolarForecasterClient) UploadEnvFile(filePath string) (map[string]int
 		return nil, fmt.Errorf("failed to close writer: %w", err)
 	}
 
-	// isteği oluştur ve content-type header'ını ayarla
-	req, err := http.NewRequest("POST", sfc.baseURL+"/upload-env", body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-
-	// isteği gönder
-	resp, err := sfc.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
-
-	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	// content-type header'ı form sınırını (boundary) içermelidir
+	headers := map[string]string{
+		"Content-Type": writer.FormDataContentType(),
 	}
 
-	return result, nil
+	return sfc.genericRequest(ctx, EndpointUploadEnv, "POST", "/upload-env", body.Bytes(), headers)
 }
 
 // RunWithEnv, /run-with-env/{session_id} endpoint'ine POST isteği gönderir.
-func (sfc *SolarForecasterClient) RunWithEnv(sessionID string, overrides map[string]interface{}) (map[string]interface{}, error) {
+func (sfc *SolarForecasterClient) RunWithEnv(ctx context.Context, sessionID string, overrides map[string]interface{}) (map[string]interface{}, error) {
 	body, err := json.Marshal(overrides)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal overrides: %w", err)
@@ -144,23 +320,190 @@ func (sfc *SolarForecasterClient) RunWithEnv(sessionID string, overrides map[str
 		"Session-ID":   sessionID,
 	}
 
-	return sfc.genericRequest("POST", "/run-with-env/"+sessionID, bytes.NewReader(body), headers)
+	return sfc.genericRequest(ctx, EndpointRunWithEnv, "POST", "/run-with-env/"+sessionID, body, headers)
 }
 
-// GetSessions, /sessions endpoint'ine GET isteği gönderir.
-func (sfc *SolarForecasterClient) GetSessions() (map[string]interface{}, error) {
-	return sfc.genericRequest("GET", "/sessions", nil, nil)
+// GetSessions, /sessions endpoint'ine GET isteği gönderir. Birden fazla
+// backend varsa tüm backend'lerin session'ları birleştirilir; yanıt
+// vermeyen backend'ler atlanır.
+func (sfc *SolarForecasterClient) GetSessions(ctx context.Context) (map[string]interface{}, error) {
+	if len(sfc.backends) == 1 {
+		result, err := sfc.genericRequest(ctx, EndpointSessions, "GET", "/sessions", nil, nil)
+		if err == nil {
+			sfc.rememberSessions(sfc.backends[0], result)
+		}
+		return result, err
+	}
+
+	results := make([]map[string]interface{}, len(sfc.backends))
+	errs := make([]error, len(sfc.backends))
+	var wg sync.WaitGroup
+	for i, b := range sfc.backends {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = sfc.backendRequest(ctx, b, EndpointSessions, "GET", "/sessions")
+		}()
+	}
+	wg.Wait()
+
+	var ok []map[string]interface{}
+	for i, b := range sfc.backends {
+		if errs[i] != nil {
+			slog.WarnContext(ctx, "error listing sessions on forecaster backend", "backend", b.url, "error", errs[i])
+			continue
+		}
+		sfc.rememberSessions(b, results[i])
+		ok = append(ok, results[i])
+	}
+	if len(ok) == 0 {
+		return nil, errs[0]
+	}
+	return mergeSessions(ok), nil
+}
+
+// SessionBackends, tüm backend'lerdeki session'ları session ID -> backend
+// adresi olarak döner. Listeyi alınamayan tek bir backend bile varsa hata
+// döner; eksik bir liste session'ları yanlışlıkla kayıp gösterirdi.
+func (sfc *SolarForecasterClient) SessionBackends(ctx context.Context) (map[string]string, error) {
+	results := make([]map[string]interface{}, len(sfc.backends))
+	errs := make([]error, len(sfc.backends))
+	var wg sync.WaitGroup
+	for i, b := range sfc.backends {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = sfc.backendRequest(ctx, b, EndpointSessions, "GET", "/sessions")
+		}()
+	}
+	wg.Wait()
+
+	owners := map[string]string{}
+	for i, b := range sfc.backends {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("failed to list sessions on %s: %w", b.url, errs[i])
+		}
+		sfc.rememberSessions(b, results[i])
+		for _, id := range SessionIDs(results[i]) {
+			owners[id] = b.url
+		}
+	}
+	return owners, nil
+}
+
+// SessionBackend, session'ı tuttuğu bilinen backend'in adresini döner.
+func (sfc *SolarForecasterClient) SessionBackend(sessionID string) string {
+	if len(sfc.backends) == 1 {
+		return sfc.backends[0].url
+	}
+	if owner, ok := sfc.sessions.Load(sessionID); ok {
+		return owner.(*backend).url
+	}
+	return ""
+}
+
+// backendRequest, gövdesiz bir isteği yalnızca verilen backend'e, devre
+// kesicisine uyarak ve metrikleriyle gönderir.
+func (sfc *SolarForecasterClient) backendRequest(ctx context.Context, b *backend, name, method, path string) (result map[string]interface{}, err error) {
+	endpoint := method + " " + path
+	ctx, span := startForecasterSpan(ctx, endpoint, "")
+	span.SetAttributes(attribute.String("forecaster.backend", b.url))
+	start := time.Now()
+	defer func() {
+		metrics.ObserveForecasterCall(endpoint, time.Since(start), err)
+		endSpan(span, err)
+	}()
+
+	if !b.breaker.Allow() {
+		return nil, &UpstreamError{Err: ErrCircuitOpen}
+	}
+	result, err = sfc.doRequest(ctx, b, name, method, path, nil, nil)
+	if ctx.Err() != nil {
+		b.breaker.Release()
+		return result, err
+	}
+	b.breaker.Record(upstreamFailure(err))
+	return result, err
 }
 
 // DeleteSession, /delete-session/{session_id} endpoint'ine DELETE isteği gönderir.
-func (sfc *SolarForecasterClient) DeleteSession(sessionID string) (map[string]interface{}, error) {
+func (sfc *SolarForecasterClient) DeleteSession(ctx context.Context, sessionID string) (map[string]interface{}, error) {
 	headers := map[string]string{
 		"Session-ID": sessionID,
 	}
-	return sfc.genericRequest("DELETE", "/sessions/"+sessionID, nil, headers)
+	return sfc.genericRequest(ctx, EndpointDeleteSession, "DELETE", "/sessions/"+sessionID, nil, headers)
 }
 
 // GetSampleEnv, /sample-env endpoint'ine GET isteği gönderir.
-func (sfc *SolarForecasterClient) GetSampleEnv() (map[string]interface{}, error) {
-	return sfc.genericRequest("GET", "/sample-env", nil, nil)
+func (sfc *SolarForecasterClient) GetSampleEnv(ctx context.Context) (map[string]interface{}, error) {
+	return sfc.genericRequest(ctx, EndpointSampleEnv, "GET", "/sample-env", nil, nil)
+}
+
+// Ping, en az bir backend'e ulaşılabildiğini doğrular ve yoklama sonucunu
+// backend'lerin sağlık durumuna yazar.
+func (sfc *SolarForecasterClient) Ping(ctx context.Context) error {
+	errs := make([]error, len(sfc.backends))
+	var wg sync.WaitGroup
+	for i, b := range sfc.backends {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = sfc.pingBackend(ctx, b)
+			b.setHealthy(errs[i] == nil)
+		}()
+	}
+	wg.Wait()
+
+	var problems []string
+	for i, b := range sfc.backends {
+		if errs[i] != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", b.url, errs[i]))
+		}
+	}
+	if len(problems) == len(sfc.backends) {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	if len(problems) > 0 {
+		slog.WarnContext(ctx, "some forecaster backends are down", "errors", problems)
+	}
+	return nil
+}
+
+// setRequestID, context'teki istek kimliğini üst servise iletir.
+func setRequestID(req *http.Request) {
+	if id := logging.RequestID(req.Context()); id != "" {
+		req.Header.Set(logging.HeaderRequestID, id)
+	}
+}
+
+// endpointTemplate, yoldaki session ID'yi {session_id} ile değiştirir; span
+// adları ve metrik etiketleri oturum başına çoğalmaz.
+func endpointTemplate(path, sessionID string) string {
+	if sessionID == "" {
+		return path
+	}
+	return strings.Replace(path, sessionID, "{session_id}", 1)
+}
+
+// startForecasterSpan, SolarForecaster çağrısı için bir istemci span'i açar.
+// endpoint, metot ve şablon yolu içerir (örn. "POST /run").
+func startForecasterSpan(ctx context.Context, endpoint, sessionID string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{}
+	if sessionID != "" {
+		attrs = append(attrs, attribute.String("session.id", sessionID))
+	}
+	attrs = append(attrs, attribute.String("forecaster.endpoint", endpoint))
+	return tracing.Tracer().Start(ctx, "SolarForecaster "+endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// endSpan, hata varsa span'e işler ve span'i kapatır.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
 }
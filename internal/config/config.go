@@ -1,27 +1,85 @@
 package config
 
 import (
-	"log"
+	"fmt"
+	"log/slog"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	AppPort            string
-	VictoriaMetricsURL string
-	SolarForecasterURL string
-	DBHost             string
-	DBUser             string
-	DBPassword         string
-	DBName             string
-	DBPort             string
+	AppPort                  string
+	VictoriaMetricsURL       string
+	SolarForecasterURLs      []string
+	ForecasterBalancer       string
+	ForecasterHealthInterval time.Duration
+	DBHost                   string
+	DBUser                   string
+	DBPassword               string
+	DBName                   string
+	DBPort                   string
+	AuthEnabled              bool
+	JWTSecret                string
+	AdminAPIKey              string
+	StreamInterval           time.Duration
+	ShutdownTimeout          time.Duration
+	HealthCacheTTL           time.Duration
+	HealthCheckTimeout       time.Duration
+	LogLevel                 string
+	TracingEnabled           bool
+	OTLPEndpoint             string
+	TracingServiceName       string
+	TracingSampleRatio       float64
+
+	VMQueryTimeout             time.Duration
+	PrometheusCheckTimeout     time.Duration
+	PrometheusCheckAllowlist   []string
+	ForecasterTimeout          time.Duration
+	ForecasterTimeouts         map[string]time.Duration
+	ForecasterRetryAttempts    map[string]int
+	ForecasterRetryBaseDelay   time.Duration
+	ForecasterRetryMaxDelay    time.Duration
+	ForecasterBreakerThreshold int
+	ForecasterBreakerCooldown  time.Duration
+
+	IdempotencyTTL         time.Duration
+	IdempotencyLockTimeout time.Duration
+
+	SessionSyncInterval time.Duration
+	SessionTTL          time.Duration
+	SessionReapInterval time.Duration
+	SessionReapDryRun   bool
+
+	QualityMode     string
+	QualityMinScore float64
+	QualityStep     time.Duration
+	QualityMinGap   time.Duration
+	QualityFlatLine time.Duration
+	QualityMaxValue float64
+	QualityOutlierZ float64
+
+	TrainingSnapshots    bool
+	TrainingSnapshotStep time.Duration
+
+	BaselineFallback bool
+	BaselineStep     time.Duration
+
+	ForecastProvider   string
+	ForecastFileDir    string
+	ForecastFileMaxAge time.Duration
+	EnsembleWeights    string
+
+	PerformanceRatioMin float64
 }
 
 func LoadConfig() *Config {
 	err := godotenv.Load()
 	if err != nil {
-		log.Printf("Error loading .env file: %v", err)
+		slog.Warn("error loading .env file", "error", err)
 	}
 
 	appPort := os.Getenv("APP_PORT")
@@ -34,12 +92,25 @@ func LoadConfig() *Config {
 		victoriaMetricsURL = "http://localhost:8428" // Varsayılan VictoriaMetrics URL'si
 	}
 
-	// SolarForecaster URL'sini al
-	solarForecasterURL := os.Getenv("SOLAR_FORECASTER_URL")
-	if solarForecasterURL == "" {
-		solarForecasterURL = "http://10.67.67.192:4545" // Varsayılan SolarForecaster URL'si
+	// SolarForecaster URL'lerini al; SOLAR_FORECASTER_URLS virgülle ayrılmış
+	// bir kopya listesidir, yoksa tek URL'li SOLAR_FORECASTER_URL kullanılır
+	solarForecasterURLs := listEnv("SOLAR_FORECASTER_URLS")
+	if len(solarForecasterURLs) == 0 {
+		solarForecasterURL := os.Getenv("SOLAR_FORECASTER_URL")
+		if solarForecasterURL == "" {
+			solarForecasterURL = "http://10.67.67.192:4545" // Varsayılan SolarForecaster URL'si
+		}
+		solarForecasterURLs = []string{solarForecasterURL}
 	}
 
+	// Kopyalar arasında yük dağıtımı: round_robin veya least_in_flight
+	forecasterBalancer := os.Getenv("FORECASTER_BALANCER")
+	if forecasterBalancer == "" {
+		forecasterBalancer = "round_robin"
+	}
+	// Kopyaların sağlık yoklaması aralığı
+	forecasterHealthInterval := durationEnv("FORECASTER_HEALTH_INTERVAL", 10*time.Second)
+
 	dbHost := os.Getenv("DB_HOST")
 	if dbHost == "" {
 		dbHost = "localhost" // Varsayılan DB host
@@ -65,14 +136,346 @@ func LoadConfig() *Config {
 		dbPort = "5432" // Varsayılan DB port
 	}
 
+	// Kimlik doğrulama varsayılan olarak açıktır
+	authEnabled := boolEnv("AUTH_ENABLED", true)
+
+	// JWT imzalama anahtarı; boşsa bearer JWT'ler kabul edilmez
+	jwtSecret := os.Getenv("JWT_SECRET")
+
+	// İlk admin anahtarı; veritabanında hiç anahtar yokken erişim sağlamak için
+	adminAPIKey := os.Getenv("ADMIN_API_KEY")
+
+	// Canlı panel akışının VictoriaMetrics'i sorgulama aralığı
+	streamInterval := durationEnv("STREAM_INTERVAL", 10*time.Second)
+
+	// Kapanışta devam eden isteklerin ve kayıtların bekleneceği süre
+	shutdownTimeout := durationEnv("SHUTDOWN_TIMEOUT", 30*time.Second)
+
+	// Hazırlık kontrollerinin önbellek süresi ve kontrol başına zaman aşımı
+	healthCacheTTL := durationEnv("HEALTH_CACHE_TTL", 5*time.Second)
+	healthCheckTimeout := durationEnv("HEALTH_CHECK_TIMEOUT", 2*time.Second)
+
+	// Log seviyesi: debug, info, warn veya error
+	logLevel := os.Getenv("LOG_LEVEL")
+	if logLevel == "" {
+		logLevel = "info"
+	}
+
+	// OpenTelemetry tracing varsayılan olarak kapalıdır
+	tracingEnabled := boolEnv("TRACING_ENABLED", false)
+	otlpEndpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	tracingServiceName := os.Getenv("OTEL_SERVICE_NAME")
+	if tracingServiceName == "" {
+		tracingServiceName = "solar-scope"
+	}
+	tracingSampleRatio := floatEnv("TRACING_SAMPLE_RATIO", 1.0)
+
+	// Çağrı türüne göre zaman aşımları. İstemci bağlantıyı kapatırsa çağrılar
+	// bu sürelerden önce de iptal edilir.
+	vmQueryTimeout := durationEnv("VM_QUERY_TIMEOUT", 5*time.Second)
+	// /forecaster/run isteklerindeki PROMETHEUS_URL'nin erişilebilirlik
+	// kontrolünün süresi; 0 kontrolü kapatır
+	prometheusCheckTimeout := optionalDurationEnv("PROMETHEUS_CHECK_TIMEOUT", 3*time.Second)
+	// Kontrolün denenebileceği taban adresler; çağıranların solar-scope'a
+	// rastgele iç adreslere istek attırmasını önler. Varsayılan VictoriaMetrics'tir.
+	prometheusCheckAllowlist := listEnv("PROMETHEUS_CHECK_ALLOWLIST")
+	if len(prometheusCheckAllowlist) == 0 {
+		prometheusCheckAllowlist = []string{victoriaMetricsURL}
+	}
+	forecasterTimeout := durationEnv("FORECASTER_TIMEOUT", 60*time.Second)
+	// Uç nokta bazında geçersiz kılmalar (örn. "run=5m,sessions=5s")
+	forecasterTimeouts := durationsEnv("FORECASTER_TIMEOUTS", map[string]time.Duration{
+		"sessions":       10 * time.Second,
+		"delete-session": 10 * time.Second,
+		"sample-env":     10 * time.Second,
+	})
+
+	// SolarForecaster uç noktalarının deneme sayıları (örn. "sessions=3,sample-env=3").
+	// Listede olmayan uç noktalar bir kez denenir; /run pahalı olduğu için
+	// varsayılan olarak yeniden denenmez.
+	forecasterRetryAttempts := attemptsEnv("FORECASTER_RETRY_ATTEMPTS", map[string]int{
+		"sessions":   3,
+		"sample-env": 3,
+	})
+	forecasterRetryBaseDelay := durationEnv("FORECASTER_RETRY_BASE_DELAY", 200*time.Millisecond)
+	forecasterRetryMaxDelay := durationEnv("FORECASTER_RETRY_MAX_DELAY", 2*time.Second)
+
+	// Art arda bu kadar hatadan sonra devre açılır; 0 devre kesiciyi kapatır
+	forecasterBreakerThreshold := intEnv("FORECASTER_BREAKER_THRESHOLD", 5)
+	forecasterBreakerCooldown := durationEnv("FORECASTER_BREAKER_COOLDOWN", 30*time.Second)
+
+	// Idempotency-Key yanıtlarının saklanma süresi ve işlenmekte görünen bir
+	// anahtarın terk edilmiş sayılacağı süre
+	idempotencyTTL := durationEnv("IDEMPOTENCY_TTL", 24*time.Hour)
+	idempotencyLockTimeout := durationEnv("IDEMPOTENCY_LOCK_TIMEOUT", 10*time.Minute)
+
+	// Yerel session kayıtlarının forecaster listesiyle karşılaştırılma aralığı; 0 kapatır
+	sessionSyncInterval := optionalDurationEnv("SESSION_SYNC_INTERVAL", 5*time.Minute)
+
+	// Kullanılmayan session'ların varsayılan ömrü (session veya tenant kendi
+	// süresini tanımlayabilir; 0 arka plandaki temizliği kapatır), temizlik
+	// aralığı ve dry-run modu
+	sessionTTL := optionalDurationEnv("SESSION_TTL", 7*24*time.Hour)
+	sessionReapInterval := durationEnv("SESSION_REAP_INTERVAL", time.Hour)
+	sessionReapDryRun := boolEnv("SESSION_REAP_DRY_RUN", false)
+
+	// Tahmin öncesi eğitim verisi kalite kontrolü: off, warn veya block
+	qualityMode := os.Getenv("QUALITY_MODE")
+	switch qualityMode {
+	case "off", "warn", "block":
+	case "":
+		qualityMode = "warn"
+	default:
+		slog.Warn("invalid QUALITY_MODE, using warn", "value", qualityMode)
+		qualityMode = "warn"
+	}
+	qualityMinScore := floatEnv("QUALITY_MIN_SCORE", 0.8)
+	qualityStep := durationEnv("QUALITY_STEP", 5*time.Minute)
+	qualityMinGap := durationEnv("QUALITY_MIN_GAP", 30*time.Minute)
+	qualityFlatLine := durationEnv("QUALITY_FLATLINE", 2*time.Hour)
+	// Panel gücünün fiziksel üst sınırı (W); 0 denetlemez
+	qualityMaxValue := floatEnv("QUALITY_MAX_VALUE", 0)
+	qualityOutlierZ := floatEnv("QUALITY_OUTLIER_Z", 6)
+
+	// Tahminlerin eğitim verisinin anlık görüntüsü; istek ?snapshot ile değiştirebilir
+	trainingSnapshots := boolEnv("TRAINING_SNAPSHOTS", false)
+	trainingSnapshotStep := durationEnv("TRAINING_SNAPSHOT_STEP", time.Minute)
+
+	// Forecaster'ın devresi açıkken yerleşik baseline tahmini dön
+	baselineFallback := boolEnv("BASELINE_FALLBACK", true)
+	baselineStep := durationEnv("BASELINE_STEP", 15*time.Minute)
+
+	// Tahmin sağlayıcısı: ml, baseline, file veya ensemble; istek ?provider ile değiştirebilir
+	forecastProvider := os.Getenv("FORECAST_PROVIDER")
+	if forecastProvider == "" {
+		forecastProvider = "ml"
+	}
+	// Dış tahmin dosyalarının dizini; boşsa file sağlayıcısı kapalıdır
+	forecastFileDir := os.Getenv("FORECAST_FILE_DIR")
+	forecastFileMaxAge := durationEnv("FORECAST_FILE_MAX_AGE", 24*time.Hour)
+	ensembleWeights := os.Getenv("ENSEMBLE_WEIGHTS")
+	if ensembleWeights == "" {
+		ensembleWeights = "ml=0.7,baseline=0.3"
+	}
+
+	// Ölçülen üretimin fiziksel beklentiye oranı bunun altındaysa dizi düşük performanslıdır
+	performanceRatioMin := floatEnv("PERFORMANCE_RATIO_MIN", 0.75)
+
 	return &Config{
-		AppPort:            appPort,
-		VictoriaMetricsURL: victoriaMetricsURL,
-		SolarForecasterURL: solarForecasterURL,
-		DBHost:             dbHost,
-		DBUser:             dbUser,
-		DBPassword:         dbPassword,
-		DBName:             dbName,
-		DBPort:             dbPort,
+		AppPort:                  appPort,
+		VictoriaMetricsURL:       victoriaMetricsURL,
+		SolarForecasterURLs:      solarForecasterURLs,
+		ForecasterBalancer:       forecasterBalancer,
+		ForecasterHealthInterval: forecasterHealthInterval,
+		DBHost:                   dbHost,
+		DBUser:                   dbUser,
+		DBPassword:               dbPassword,
+		DBName:                   dbName,
+		DBPort:                   dbPort,
+		AuthEnabled:              authEnabled,
+		JWTSecret:                jwtSecret,
+		AdminAPIKey:              adminAPIKey,
+		StreamInterval:           streamInterval,
+		ShutdownTimeout:          shutdownTimeout,
+		HealthCacheTTL:           healthCacheTTL,
+		HealthCheckTimeout:       healthCheckTimeout,
+		LogLevel:                 logLevel,
+		TracingEnabled:           tracingEnabled,
+		OTLPEndpoint:             otlpEndpoint,
+		TracingServiceName:       tracingServiceName,
+		TracingSampleRatio:       tracingSampleRatio,
+
+		VMQueryTimeout:             vmQueryTimeout,
+		PrometheusCheckTimeout:     prometheusCheckTimeout,
+		PrometheusCheckAllowlist:   prometheusCheckAllowlist,
+		ForecasterTimeout:          forecasterTimeout,
+		ForecasterTimeouts:         forecasterTimeouts,
+		ForecasterRetryAttempts:    forecasterRetryAttempts,
+		ForecasterRetryBaseDelay:   forecasterRetryBaseDelay,
+		ForecasterRetryMaxDelay:    forecasterRetryMaxDelay,
+		ForecasterBreakerThreshold: forecasterBreakerThreshold,
+		ForecasterBreakerCooldown:  forecasterBreakerCooldown,
+
+		IdempotencyTTL:         idempotencyTTL,
+		IdempotencyLockTimeout: idempotencyLockTimeout,
+
+		SessionSyncInterval: sessionSyncInterval,
+		SessionTTL:          sessionTTL,
+		SessionReapInterval: sessionReapInterval,
+		SessionReapDryRun:   sessionReapDryRun,
+
+		QualityMode:     qualityMode,
+		QualityMinScore: qualityMinScore,
+		QualityStep:     qualityStep,
+		QualityMinGap:   qualityMinGap,
+		QualityFlatLine: qualityFlatLine,
+		QualityMaxValue: qualityMaxValue,
+		QualityOutlierZ: qualityOutlierZ,
+
+		TrainingSnapshots:    trainingSnapshots,
+		TrainingSnapshotStep: trainingSnapshotStep,
+
+		BaselineFallback: baselineFallback,
+		BaselineStep:     baselineStep,
+
+		ForecastProvider:   forecastProvider,
+		ForecastFileDir:    forecastFileDir,
+		ForecastFileMaxAge: forecastFileMaxAge,
+		EnsembleWeights:    ensembleWeights,
+
+		PerformanceRatioMin: performanceRatioMin,
+	}
+}
+
+// boolEnv, ortam değişkenini bool olarak okur; boş veya geçersizse def döner.
+func boolEnv(key string, def bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	parsed, err := strconv.ParseBool(v)
+	if err != nil {
+		slog.Warn("invalid boolean value, using default", "key", key, "value", v, "default", def)
+		return def
+	}
+	return parsed
+}
+
+// floatEnv, ortam değişkenini sayı olarak okur; boş veya geçersizse def döner.
+func floatEnv(key string, def float64) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	parsed, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		slog.Warn("invalid numeric value, using default", "key", key, "value", v, "default", def)
+		return def
+	}
+	return parsed
+}
+
+// listEnv, virgülle ayrılmış ortam değişkenini boş öğeleri atlayarak okur.
+func listEnv(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// intEnv, ortam değişkenini negatif olmayan bir tamsayı olarak okur; boş veya
+// geçersizse def döner.
+func intEnv(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	parsed, err := strconv.Atoi(v)
+	if err != nil || parsed < 0 {
+		slog.Warn("invalid integer value, using default", "key", key, "value", v, "default", def)
+		return def
+	}
+	return parsed
+}
+
+// attemptsEnv, "ad=sayı" çiftlerinden oluşan virgülle ayrılmış listeyi okur;
+// boşsa def döner.
+func attemptsEnv(key string, def map[string]int) map[string]int {
+	attempts := map[string]int{}
+	ok := pairsEnv(key, func(name, value string) bool {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return false
+		}
+		attempts[name] = n
+		return true
+	})
+	if !ok {
+		return def
+	}
+	return attempts
+}
+
+// durationsEnv, "ad=süre" çiftlerinden oluşan virgülle ayrılmış listeyi okur;
+// boşsa def döner.
+func durationsEnv(key string, def map[string]time.Duration) map[string]time.Duration {
+	durations := map[string]time.Duration{}
+	ok := pairsEnv(key, func(name, value string) bool {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return false
+		}
+		durations[name] = d
+		return true
+	})
+	if !ok {
+		return def
+	}
+	return durations
+}
+
+// pairsEnv, virgülle ayrılmış "ad=değer" çiftlerini set'e verir; set'in
+// reddettiği çiftler uyarıyla atlanır. Değişken boşsa false döner.
+func pairsEnv(key string, set func(name, value string) bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return false
+	}
+	for _, pair := range strings.Split(v, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" || !set(name, value) {
+			slog.Warn("invalid entry, skipping", "key", key, "entry", pair)
+		}
+	}
+	return true
+}
+
+// durationEnv, ortam değişkenini süre olarak okur; boş veya geçersizse def döner.
+func durationEnv(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	parsed, err := time.ParseDuration(v)
+	if err != nil || parsed <= 0 {
+		slog.Warn("invalid duration value, using default", "key", key, "value", v, "default", def.String())
+		return def
+	}
+	return parsed
+}
+
+// optionalDurationEnv, durationEnv gibidir ancak 0'ı özelliğin kapalı olduğu
+// anlamında kabul eder.
+func optionalDurationEnv(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	parsed, err := time.ParseDuration(v)
+	if err != nil || parsed < 0 {
+		slog.Warn("invalid duration value, using default", "key", key, "value", v, "default", def.String())
+		return def
+	}
+	return parsed
+}
+
+// String, gizli alanları maskeleyerek konfigürasyonu loglanabilir hale getirir.
+func (c Config) String() string {
+	masked := c
+	masked.DBPassword = mask(c.DBPassword)
+	masked.JWTSecret = mask(c.JWTSecret)
+	masked.AdminAPIKey = mask(c.AdminAPIKey)
+	type plain Config
+	return fmt.Sprintf("%+v", plain(masked))
+}
+
+func mask(secret string) string {
+	if secret == "" {
+		return ""
 	}
+	return "***"
 }
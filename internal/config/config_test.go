@@ -0,0 +1,51 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDurationEnv(t *testing.T) {
+	tests := []struct {
+		value        string
+		want         time.Duration
+		wantOptional time.Duration
+	}{
+		{"", time.Hour, time.Hour},
+		{"30s", 30 * time.Second, 30 * time.Second},
+		{"0", time.Hour, 0},
+		{"0s", time.Hour, 0},
+		{"-1m", time.Hour, time.Hour},
+		{"soon", time.Hour, time.Hour},
+	}
+	for _, tt := range tests {
+		t.Setenv("TEST_DURATION", tt.value)
+		if got := durationEnv("TEST_DURATION", time.Hour); got != tt.want {
+			t.Errorf("durationEnv(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+		if got := optionalDurationEnv("TEST_DURATION", time.Hour); got != tt.wantOptional {
+			t.Errorf("optionalDurationEnv(%q) = %v, want %v", tt.value, got, tt.wantOptional)
+		}
+	}
+}
+
+func TestSessionTTLZeroDisables(t *testing.T) {
+	t.Setenv("SESSION_TTL", "0")
+	if cfg := LoadConfig(); cfg.SessionTTL != 0 {
+		t.Errorf("SessionTTL = %v, want 0", cfg.SessionTTL)
+	}
+}
+
+func TestSessionSyncIntervalZeroDisables(t *testing.T) {
+	t.Setenv("SESSION_SYNC_INTERVAL", "0")
+	if cfg := LoadConfig(); cfg.SessionSyncInterval != 0 {
+		t.Errorf("SessionSyncInterval = %v, want 0", cfg.SessionSyncInterval)
+	}
+}
+
+func TestPrometheusCheckTimeoutZeroDisables(t *testing.T) {
+	t.Setenv("PROMETHEUS_CHECK_TIMEOUT", "0")
+	if cfg := LoadConfig(); cfg.PrometheusCheckTimeout != 0 {
+		t.Errorf("PrometheusCheckTimeout = %v, want 0", cfg.PrometheusCheckTimeout)
+	}
+}
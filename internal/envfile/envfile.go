@@ -0,0 +1,161 @@
+package envfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// redactedValue, maskelenen değerlerin yerine yazılır.
+const redactedValue = "***"
+
+// sensitiveMarkers, adında geçtiğinde değeri gizli sayılan anahtar parçalarıdır.
+var sensitiveMarkers = []string{"PASSWORD", "PASSWD", "SECRET", "TOKEN", "API_KEY", "APIKEY", "CREDENTIAL", "PRIVATE"}
+
+// Parse, KEY=VALUE satırlarından oluşan bir env dosyasını okur. Boş satırlar,
+// # ile başlayan yorumlar ve "export " öneki atlanır; tırnaklı değerler açılır.
+// Aynı anahtar birden fazla tanımlanmışsa son tanım geçerlidir.
+func Parse(content []byte) map[string]string {
+	values := map[string]string{}
+	for _, line := range strings.Split(string(content), "\n") {
+		key, value, ok := Assignment(line)
+		if !ok {
+			continue
+		}
+		values[key] = Unquote(value)
+	}
+	return values
+}
+
+// Assignment, tek bir satırı anahtar ve ham (tırnakları açılmamış) değere
+// ayırır. Boş satırlarda, yorumlarda ve = içermeyen satırlarda ok false
+// döner. "export" öneki ve ardındaki boşluklar atlanır.
+func Assignment(line string) (key, value string, ok bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false
+	}
+	if rest, found := strings.CutPrefix(line, "export"); found && rest != strings.TrimLeft(rest, " \t") {
+		line = strings.TrimLeft(rest, " \t")
+	}
+	key, value, ok = strings.Cut(line, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", "", false
+	}
+	return key, strings.TrimSpace(value), true
+}
+
+// Format, değerleri anahtara göre sıralı KEY=VALUE satırlarına yazar. Baştaki
+// veya sondaki boşluklar ve # gibi yorum olarak okunabilecek değerler
+// tırnaklanır; Parse ile okunduğunda aynı değerler elde edilir.
+func Format(values map[string]string) []byte {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(quote(values[key]))
+		b.WriteByte('\n')
+	}
+	return []byte(b.String())
+}
+
+// quote, değeri gerekirse tırnaklar.
+func quote(value string) string {
+	if value != strings.TrimSpace(value) || strings.Contains(value, "#") || Unquote(value) != value {
+		if !strings.Contains(value, "'") {
+			return "'" + value + "'"
+		}
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// Unquote, çift veya tek tırnaklı bir değerin tırnaklarını kaldırır.
+func Unquote(value string) string {
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		return unquoted
+	}
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// Redact, gizli görünen anahtarların değerlerini ve URL'lerdeki parolaları
+// maskeler. Girdi değiştirilmez.
+func Redact(values map[string]string) map[string]string {
+	redacted := make(map[string]string, len(values))
+	for key, value := range values {
+		switch {
+		case sensitive(key):
+			redacted[key] = redactedValue
+		default:
+			redacted[key] = redactURL(value)
+		}
+	}
+	return redacted
+}
+
+func sensitive(key string) bool {
+	upper := strings.ToUpper(key)
+	for _, marker := range sensitiveMarkers {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactURL, değer kullanıcı bilgisi içeren bir URL ise parolayı maskeler.
+func redactURL(value string) string {
+	if !strings.Contains(value, "://") || !strings.Contains(value, "@") {
+		return value
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return value
+	}
+	if _, hasPassword := u.User.Password(); !hasPassword {
+		return value
+	}
+	// url.UserPassword maskeyi kaçışlayacağı için kullanıcı bilgisi elle yazılır
+	user := url.User(u.User.Username()).String()
+	u.User = nil
+	rest := strings.TrimPrefix(u.String(), u.Scheme+"://")
+	return u.Scheme + "://" + user + ":" + redactedValue + "@" + rest
+}
+
+// Stringify, JSON'dan gelen parametre değerlerini env değerlerine çevirir.
+// Yalnızca metin, sayı, bool ve null kabul edilir; null boş değer olur.
+func Stringify(values map[string]interface{}) (map[string]string, error) {
+	out := make(map[string]string, len(values))
+	for key, value := range values {
+		switch v := value.(type) {
+		case nil:
+			out[key] = ""
+		case string:
+			out[key] = v
+		case bool:
+			out[key] = strconv.FormatBool(v)
+		case float64:
+			out[key] = strconv.FormatFloat(v, 'f', -1, 64)
+		case int:
+			out[key] = strconv.Itoa(v)
+		case json.Number: // datatypes.JSONMap sayıları bu türle çözer
+			out[key] = v.String()
+		default:
+			return nil, fmt.Errorf("value of %s must be a string, number or boolean", key)
+		}
+	}
+	return out, nil
+}
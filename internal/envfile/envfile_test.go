@@ -0,0 +1,101 @@
+package envfile
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	content := []byte(`# yorum
+PROMETHEUS_URL=http://vm:8428
+
+export METRIC_NAME = mppt_values
+export	TRAIN_DAYS=7
+exporter=kept
+QUOTED="a # b"
+SINGLE='  padded  '
+NO_EQUALS
+=no_key
+TRAIN_DAYS=14
+`)
+	want := map[string]string{
+		"PROMETHEUS_URL": "http://vm:8428",
+		"METRIC_NAME":    "mppt_values",
+		"TRAIN_DAYS":     "14",
+		"exporter":       "kept",
+		"QUOTED":         "a # b",
+		"SINGLE":         "  padded  ",
+	}
+	if got := Parse(content); !maps.Equal(got, want) {
+		t.Errorf("Parse() = %v, want %v", got, want)
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	values := map[string]string{
+		"PLAIN":   "mppt_values",
+		"EMPTY":   "",
+		"SPACES":  "  padded ",
+		"HASH":    "a # b",
+		"QUOTES":  `"quoted"`,
+		"SINGLE":  "it's",
+		"MATCHER": `mppt_values{sensor="x"}`,
+	}
+	formatted := Format(values)
+	if got := Parse(formatted); !maps.Equal(got, values) {
+		t.Errorf("Parse(Format()) = %v, want %v\n%s", got, values, formatted)
+	}
+	if got, want := string(Format(map[string]string{"B": "2", "A": "1"})), "A=1\nB=2\n"; got != want {
+		t.Errorf("Format() = %q, want %q", got, want)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	got := Redact(map[string]string{
+		"DB_PASSWORD":    "hunter2",
+		"api_token":      "abc",
+		"PROMETHEUS_URL": "http://user:pass@vm:8428/path",
+		"NO_PASSWORD":    "",
+		"OTHER_URL":      "http://user@vm:8428",
+	})
+	want := map[string]string{
+		"DB_PASSWORD":    redactedValue,
+		"api_token":      redactedValue,
+		"PROMETHEUS_URL": "http://user:***@vm:8428/path",
+		"NO_PASSWORD":    redactedValue,
+		"OTHER_URL":      "http://user@vm:8428",
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("Redact() = %v, want %v", got, want)
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		values   map[string]string
+		want     string
+		wantErr  bool
+	}{
+		{"values", "A={{ a }}\nB={{b}}\n", map[string]string{"a": "1", "b": "2"}, "A=1\nB=2\n", false},
+		{"default", "A={{ a | 5 }}", nil, "A=5", false},
+		{"value over default", "A={{ a | 5 }}", map[string]string{"a": "1"}, "A=1", false},
+		{"repeated", "A={{a}} B={{a}}", map[string]string{"a": "1"}, "A=1 B=1", false},
+		{"missing", "A={{ a }}", nil, "", true},
+		{"line break", "A={{ a }}", map[string]string{"a": "1\nB=2"}, "", true},
+		{"unclosed", "A={{ a", map[string]string{"a": "1"}, "", true},
+		{"invalid name", "A={{ 1a }}", nil, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.template, tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Render() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package envfile
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// placeholderPattern, {{ ad }} veya varsayılan değerli {{ ad | değer }}
+// biçimindeki yer tutucuları tanır.
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*(?:\|([^}]*))?\}\}`)
+
+// Placeholders, şablondaki yer tutucu adlarını ilk geçiş sırasıyla ve
+// tekrarsız döner.
+func Placeholders(template string) []string {
+	var names []string
+	for _, match := range placeholderPattern.FindAllStringSubmatch(template, -1) {
+		if !slices.Contains(names, match[1]) {
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+// CheckTemplate, şablonda kapanmamış veya geçersiz bir yer tutucu olup
+// olmadığını denetler.
+func CheckTemplate(template string) error {
+	rest := placeholderPattern.ReplaceAllString(template, "")
+	if i := strings.Index(rest, "{{"); i >= 0 {
+		line := strings.Count(rest[:i], "\n") + 1
+		return fmt.Errorf("invalid placeholder near line %d", line)
+	}
+	return nil
+}
+
+// Render, yer tutucuları values'taki değerlerle doldurur. Değeri verilmeyen
+// yer tutucular varsayılan değerlerini alır; varsayılanı da yoksa hata döner.
+// Satır sonu içeren değerler başka anahtarlar ekleyebileceği için reddedilir.
+func Render(template string, values map[string]string) (string, error) {
+	if err := CheckTemplate(template); err != nil {
+		return "", err
+	}
+	var missing, invalid []string
+	rendered := placeholderPattern.ReplaceAllStringFunc(template, func(placeholder string) string {
+		match := placeholderPattern.FindStringSubmatch(placeholder)
+		name := match[1]
+		value, ok := values[name]
+		if !ok {
+			if !strings.Contains(placeholder, "|") {
+				if !slices.Contains(missing, name) {
+					missing = append(missing, name)
+				}
+				return placeholder
+			}
+			value = strings.TrimSpace(match[2])
+		}
+		if strings.ContainsAny(value, "\r\n") && !slices.Contains(invalid, name) {
+			invalid = append(invalid, name)
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing values for placeholders: %s", strings.Join(missing, ", "))
+	}
+	if len(invalid) > 0 {
+		return "", fmt.Errorf("values must not contain line breaks: %s", strings.Join(invalid, ", "))
+	}
+	return rendered, nil
+}
@@ -0,0 +1,186 @@
+package envfile
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"solar-scope/internal/client"
+	"solar-scope/internal/validate"
+	"strconv"
+	"strings"
+)
+
+// Problem, env dosyasındaki tek bir geçersiz alanı açıklar.
+type Problem struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// keyPattern, geçerli env anahtarlarını tanır.
+var keyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// valueKind, bilinen bir anahtarın beklenen değer türüdür.
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindURL
+	kindPromQL
+	kindInt
+	kindFloat
+	kindBool
+)
+
+// rule, SolarForecaster'ın tanıdığı bir parametrenin türü ve validate
+// paketinin etiket söz dizimindeki kurallarıdır.
+type rule struct {
+	kind valueKind
+	tag  string
+}
+
+// rules, SolarForecaster'ın beklediği parametrelerdir. Türler ve sınırlar
+// client.RunRequest'in alanlarından türetilir; env dosyası ile /run gövdesi
+// böylece aynı kurallarla denetlenir. Listede olmayan anahtarlar olduğu gibi
+// iletilir.
+var rules = fieldRules(reflect.TypeOf(client.RunRequest{}))
+
+// fieldRules, struct'ın JSON adlı alanlarından kuralları çıkarır; gömülü
+// struct'ların alanlarını da ekler.
+func fieldRules(t reflect.Type) map[string]rule {
+	rules := map[string]rule{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			maps.Copy(rules, fieldRules(field.Type))
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		r := rule{kind: kindString, tag: field.Tag.Get("validate")}
+		switch field.Type.Kind() {
+		case reflect.Bool:
+			r.kind = kindBool
+		case reflect.Int:
+			r.kind = kindInt
+		case reflect.Float64:
+			r.kind = kindFloat
+		case reflect.String:
+			if r.has("url") {
+				r.kind = kindURL
+			} else if r.has("promql") {
+				r.kind = kindPromQL
+			}
+		}
+		rules[name] = r
+	}
+	return rules
+}
+
+// has, etiketin verilen kuralı içerip içermediğini söyler.
+func (r rule) has(name string) bool {
+	return slices.Contains(strings.Split(r.tag, ","), name)
+}
+
+// ValidateContent, env dosyasının her satırının KEY=VALUE biçiminde olduğunu
+// ve değerlerin Validate kurallarına uyduğunu denetler.
+func ValidateContent(content []byte) []Problem {
+	problems := []Problem{}
+	seen := map[string]bool{}
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, _, ok := Assignment(line)
+		field := fmt.Sprintf("line %d", i+1)
+		switch {
+		case !ok:
+			problems = append(problems, Problem{Field: field, Message: "expected KEY=VALUE"})
+		case !keyPattern.MatchString(key):
+			problems = append(problems, Problem{Field: field, Message: fmt.Sprintf("invalid key %q", key)})
+		case seen[key]:
+			problems = append(problems, Problem{Field: key, Message: fmt.Sprintf("defined more than once (again on line %d)", i+1)})
+		}
+		seen[key] = true
+	}
+	return append(problems, Validate(Parse(content))...)
+}
+
+// Validate, bilinen parametrelerin varlığını, türünü ve aralığını denetler.
+// Sorunlar alan adına göre sıralı döner.
+func Validate(values map[string]string) []Problem {
+	var problems []Problem
+	for key, r := range rules {
+		value, ok := values[key]
+		if !ok || strings.TrimSpace(value) == "" {
+			if r.has("required") {
+				problems = append(problems, Problem{Field: key, Message: "is required"})
+			}
+			continue
+		}
+		if msg := r.check(strings.TrimSpace(value)); msg != "" {
+			problems = append(problems, Problem{Field: key, Message: msg})
+		}
+	}
+	for key, value := range values {
+		if !keyPattern.MatchString(key) {
+			problems = append(problems, Problem{Field: key, Message: "is not a valid env key"})
+		} else if strings.ContainsAny(value, "\r\n") {
+			problems = append(problems, Problem{Field: key, Message: "must not contain line breaks"})
+		} else if value == redactedValue {
+			problems = append(problems, Problem{Field: key, Message: "is a redacted placeholder, supply the real value"})
+		}
+	}
+	slices.SortFunc(problems, func(a, b Problem) int { return strings.Compare(a.Field, b.Field) })
+	return problems
+}
+
+func (r rule) check(value string) string {
+	var typed interface{} = value
+	switch r.kind {
+	case kindBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "must be true or false"
+		}
+		typed = b
+	case kindInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return "must be an integer"
+		}
+		typed = n
+	case kindFloat:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "must be a number"
+		}
+		typed = n
+	}
+	return validate.Value(typed, r.tag)
+}
+
+// Typed, bilinen sayısal ve bool parametreleri JSON türlerine çevirir; diğer
+// değerler ve çevrilemeyenler metin olarak kalır.
+func Typed(values map[string]string) map[string]interface{} {
+	typed := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		typed[key] = value
+		switch rules[key].kind {
+		case kindInt:
+			if n, err := strconv.Atoi(value); err == nil {
+				typed[key] = n
+			}
+		case kindFloat:
+			if n, err := strconv.ParseFloat(value, 64); err == nil {
+				typed[key] = n
+			}
+		case kindBool:
+			if b, err := strconv.ParseBool(value); err == nil {
+				typed[key] = b
+			}
+		}
+	}
+	return typed
+}
@@ -0,0 +1,95 @@
+package envfile
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	valid := func() map[string]string {
+		return map[string]string{
+			"PROMETHEUS_URL":      "http://vm:8428",
+			"METRIC_NAME":         `mppt_values{sensor="panel gucu"}`,
+			"TRAIN_DAYS":          "7",
+			"BATTERY_CAPACITY_WH": "5000",
+			"CHARGE_EFFICIENCY":   "0.95",
+			"DETAILED_SUMMARY":    "true",
+			"EXTRA_SETTING":       "anything",
+		}
+	}
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		want  []Problem
+	}{
+		{"valid", "", "", nil},
+		{"missing url", "PROMETHEUS_URL", "", []Problem{{"PROMETHEUS_URL", "is required"}}},
+		{"invalid url", "PROMETHEUS_URL", "ftp://vm", []Problem{{"PROMETHEUS_URL", "must be an http or https URL"}}},
+		{"non-integer days", "TRAIN_DAYS", "7.5", []Problem{{"TRAIN_DAYS", "must be an integer"}}},
+		{"days too low", "TRAIN_DAYS", "0", []Problem{{"TRAIN_DAYS", "must be at least 1"}}},
+		{"days too high", "TRAIN_DAYS", "366", []Problem{{"TRAIN_DAYS", "must be at most 365"}}},
+		{"zero capacity", "BATTERY_CAPACITY_WH", "0", []Problem{{"BATTERY_CAPACITY_WH", "must be greater than 0"}}},
+		{"efficiency above one", "CHARGE_EFFICIENCY", "1.2", []Problem{{"CHARGE_EFFICIENCY", "must be at most 1"}}},
+		{"invalid bool", "DETAILED_SUMMARY", "yes", []Problem{{"DETAILED_SUMMARY", "must be true or false"}}},
+		{"redacted", "DB_PASSWORD", redactedValue, []Problem{{"DB_PASSWORD", "is a redacted placeholder, supply the real value"}}},
+		{"line break", "EXTRA_SETTING", "a\nB=1", []Problem{{"EXTRA_SETTING", "must not contain line breaks"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := valid()
+			if tt.key != "" {
+				values[tt.key] = tt.value
+			}
+			if got := Validate(values); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePromQL(t *testing.T) {
+	problems := Validate(map[string]string{"PROMETHEUS_URL": "http://vm:8428", "METRIC_NAME": "sum(("})
+	if len(problems) != 1 || problems[0].Field != "METRIC_NAME" {
+		t.Errorf("Validate() = %v, want one METRIC_NAME problem", problems)
+	}
+}
+
+func TestValidateContent(t *testing.T) {
+	content := []byte(`PROMETHEUS_URL=http://vm:8428
+export METRIC_NAME=mppt_values
+not an assignment
+1KEY=x
+TRAIN_DAYS=7
+TRAIN_DAYS=8
+`)
+	want := []Problem{
+		{"line 3", "expected KEY=VALUE"},
+		{"line 4", `invalid key "1KEY"`},
+		{"TRAIN_DAYS", "defined more than once (again on line 6)"},
+		{"1KEY", "is not a valid env key"},
+	}
+	if got := ValidateContent(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidateContent() = %v, want %v", got, want)
+	}
+}
+
+func TestTyped(t *testing.T) {
+	got := Typed(map[string]string{
+		"TRAIN_DAYS":          "7",
+		"BATTERY_CAPACITY_WH": "5000.5",
+		"USE_CYTHON":          "false",
+		"CONSTANT_LOAD_W":     "abc",
+		"EXTRA_SETTING":       "42",
+	})
+	want := map[string]interface{}{
+		"TRAIN_DAYS":          7,
+		"BATTERY_CAPACITY_WH": 5000.5,
+		"USE_CYTHON":          false,
+		"CONSTANT_LOAD_W":     "abc",
+		"EXTRA_SETTING":       "42",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Typed() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,123 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Durum değerleri
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusDegraded = "degraded"
+)
+
+// CheckFunc, bir bağımlılığa ulaşılabildiğini doğrular.
+type CheckFunc func(ctx context.Context) error
+
+// Result, tek bir kontrolün son sonucudur. Error, DSN ve host bilgisi
+// içerebildiği için kimlik doğrulamasız yanıta yazılmaz; yalnızca loglanır.
+type Result struct {
+	Status    string    `json:"status"`
+	Critical  bool      `json:"critical"`
+	LatencyMs float64   `json:"latency_ms"`
+	Error     string    `json:"-"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report, tüm kontrollerin birleşik sonucudur.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+type check struct {
+	name     string
+	critical bool
+	fn       CheckFunc
+
+	mu     sync.Mutex
+	result Result
+	cached bool
+}
+
+// Checker, kayıtlı kontrolleri çalıştırır ve sonuçlarını ttl süresince
+// önbellekte tutar. Böylece sık probe istekleri bağımlılıkları yormaz.
+type Checker struct {
+	ttl     time.Duration
+	timeout time.Duration
+	checks  []*check
+}
+
+// NewChecker, sonuçları ttl süresince önbellekleyen ve her kontrole en fazla
+// timeout süre tanıyan bir Checker oluşturur.
+func NewChecker(ttl, timeout time.Duration) *Checker {
+	return &Checker{ttl: ttl, timeout: timeout}
+}
+
+// Register, yeni bir kontrol ekler. Kritik bir kontrol başarısız olursa
+// hazırlık durumu down olur; kritik olmayanlar yalnızca degraded yapar.
+func (hc *Checker) Register(name string, critical bool, fn CheckFunc) {
+	hc.checks = append(hc.checks, &check{name: name, critical: critical, fn: fn})
+}
+
+// Check, tüm kontrolleri paralel çalıştırır (önbellekte taze sonuç yoksa).
+func (hc *Checker) Check(ctx context.Context) Report {
+	report := Report{Status: StatusUp, Checks: make(map[string]Result, len(hc.checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range hc.checks {
+		wg.Add(1)
+		go func(c *check) {
+			defer wg.Done()
+			result := hc.run(ctx, c)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[c.name] = result
+			if result.Status == StatusDown {
+				if c.critical {
+					report.Status = StatusDown
+				} else if report.Status == StatusUp {
+					report.Status = StatusDegraded
+				}
+			}
+		}(c)
+	}
+	wg.Wait()
+	return report
+}
+
+// run, kontrolü çalıştırır. Aynı anda gelen istekler tek bir çalıştırmayı paylaşır.
+func (hc *Checker) run(ctx context.Context, c *check) Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cached && time.Since(c.result.CheckedAt) < hc.ttl {
+		return c.result
+	}
+
+	// Sonuç diğer isteklerle paylaşıldığı için çağıranın iptali kontrolü
+	// yarıda kesip önbelleğe sahte bir hata yazmamalı
+	checkCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), hc.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.fn(checkCtx)
+	result := Result{
+		Status:    StatusUp,
+		Critical:  c.critical,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+		CheckedAt: start,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	c.result = result
+	c.cached = true
+	return result
+}
@@ -0,0 +1,95 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func ok(context.Context) error   { return nil }
+func fail(context.Context) error { return errors.New("dial tcp 10.0.0.5:5432: connection refused") }
+
+func TestCheckStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		critical CheckFunc
+		optional CheckFunc
+		want     string
+	}{
+		{"all up", ok, ok, StatusUp},
+		{"optional down", ok, fail, StatusDegraded},
+		{"critical down", fail, ok, StatusDown},
+		{"both down", fail, fail, StatusDown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := NewChecker(time.Minute, time.Second)
+			hc.Register("database", true, tt.critical)
+			hc.Register("forecaster", false, tt.optional)
+
+			report := hc.Check(context.Background())
+			if report.Status != tt.want {
+				t.Errorf("Status = %q, want %q", report.Status, tt.want)
+			}
+			if len(report.Checks) != 2 {
+				t.Errorf("Checks = %d, want 2", len(report.Checks))
+			}
+		})
+	}
+}
+
+func TestCheckCachesWithinTTL(t *testing.T) {
+	var calls atomic.Int32
+	hc := NewChecker(50*time.Millisecond, time.Second)
+	hc.Register("database", true, func(context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+
+	for i := 0; i < 3; i++ {
+		hc.Check(context.Background())
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("calls within TTL = %d, want 1", got)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	hc.Check(context.Background())
+	if got := calls.Load(); got != 2 {
+		t.Errorf("calls after TTL = %d, want 2", got)
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	hc := NewChecker(time.Minute, 10*time.Millisecond)
+	hc.Register("victoriametrics", true, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	report := hc.Check(context.Background())
+	if report.Status != StatusDown {
+		t.Errorf("Status = %q, want %q", report.Status, StatusDown)
+	}
+}
+
+func TestReportHidesErrors(t *testing.T) {
+	hc := NewChecker(time.Minute, time.Second)
+	hc.Register("database", true, fail)
+
+	report := hc.Check(context.Background())
+	if report.Checks["database"].Error == "" {
+		t.Fatal("Error was not recorded")
+	}
+	body, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(body), "10.0.0.5") {
+		t.Errorf("report leaks check error: %s", body)
+	}
+}
@@ -0,0 +1,123 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/url"
+	"solar-scope/database"
+	"solar-scope/internal/apierror"
+	"solar-scope/internal/auth"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// Header, istemcinin tekrar eden istekleri işaretlediği başlıktır.
+	Header = "Idempotency-Key"
+	// HeaderReplayed, yanıtın saklanan bir yanıttan döndüğünü belirtir.
+	HeaderReplayed = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+)
+
+// Middleware, Idempotency-Key başlığı taşıyan istekleri tekilleştirir. Aynı
+// anahtar ve aynı istekle ttl içinde gelen tekrarlar handler çağrılmadan
+// saklanan yanıtı alır; aynı anahtar farklı bir istekle gelirse veya ilk
+// istek hâlâ sürüyorsa 409 döner. Anahtarlar tenant başınadır. lockTimeout'tan
+// uzun süredir işlenmekte görünen anahtarlar (örn. süreç çöktüyse) terk
+// edilmiş sayılır. 5xx yanıtlar saklanmaz; istemci aynı anahtarla yeniden
+// deneyebilir.
+func Middleware(ttl, lockTimeout time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(Header)
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > maxKeyLength {
+			return apierror.Write(c, fiber.StatusBadRequest, apierror.CodeBadRequest, "Idempotency-Key must be at most 255 characters")
+		}
+
+		tenantID := auth.PrincipalFrom(c).TenantID
+		hash := requestHash(c)
+		existing, err := database.ClaimIdempotencyKey(c.UserContext(), tenantID, key, hash, ttl)
+		if err == nil && existing != nil && existing.StatusCode == 0 && time.Since(existing.CreatedAt) > lockTimeout {
+			var took bool
+			took, err = database.TakeOverIdempotencyKey(c.UserContext(), tenantID, key, hash, ttl, time.Now().Add(-lockTimeout))
+			if took {
+				slog.WarnContext(c.UserContext(), "took over abandoned idempotency key", "key", key)
+				existing = nil
+			} else if err == nil {
+				// Başka bir istek anahtarı devraldı veya tamamladı; güncel kaydı oku
+				existing, err = database.ClaimIdempotencyKey(c.UserContext(), tenantID, key, hash, ttl)
+			}
+		}
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "error claiming idempotency key", "error", err)
+			return apierror.Write(c, fiber.StatusInternalServerError, apierror.CodeInternal, "Failed to check Idempotency-Key")
+		}
+
+		if existing != nil {
+			switch {
+			case existing.RequestHash != hash:
+				return apierror.Write(c, fiber.StatusConflict, apierror.CodeConflict, "Idempotency-Key was already used with a different request")
+			case existing.StatusCode == 0:
+				return apierror.Write(c, fiber.StatusConflict, apierror.CodeConflict, "A request with this Idempotency-Key is still being processed")
+			}
+			c.Set(HeaderReplayed, "true")
+			c.Set(fiber.HeaderContentType, existing.ContentType)
+			return c.Status(existing.StatusCode).Send(existing.Response)
+		}
+
+		// Anahtar istek iptal edilse de serbest bırakılmalı veya tamamlanmalı
+		ctx := context.WithoutCancel(c.UserContext())
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if err := database.ReleaseIdempotencyKey(ctx, tenantID, key); err != nil {
+				slog.ErrorContext(ctx, "error releasing idempotency key", "key", key, "error", err)
+			}
+		}()
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError || status == apierror.StatusClientClosedRequest {
+			return nil
+		}
+
+		body := append([]byte(nil), c.Response().Body()...)
+		contentType := string(c.Response().Header.ContentType())
+		if err := database.CompleteIdempotencyKey(ctx, tenantID, key, status, contentType, body); err != nil {
+			slog.ErrorContext(ctx, "error storing idempotent response", "key", key, "error", err)
+			return nil
+		}
+		completed = true
+		return nil
+	}
+}
+
+// requestHash, isteğin metot, yol, sorgu dizesi ve gövdesinden bir özet
+// üretir. Sorgu parametreleri (örn. ?provider) anahtara göre sıralanarak
+// eklenir; yalnızca sırası farklı olan istekler aynı sayılır. Aynı anahtar
+// farklı bir uç noktada kullanılırsa da çakışma olarak görülür.
+func requestHash(c *fiber.Ctx) string {
+	query := string(c.Request().URI().QueryString())
+	if values, err := url.ParseQuery(query); err == nil {
+		query = values.Encode()
+	}
+	h := sha256.New()
+	h.Write([]byte(c.Method()))
+	h.Write([]byte{0})
+	h.Write([]byte(c.Path()))
+	h.Write([]byte{0})
+	h.Write([]byte(query))
+	h.Write([]byte{0})
+	h.Write(c.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,194 @@
+package idempotency
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"solar-scope/database"
+	"solar-scope/internal/auth"
+	"solar-scope/internal/config"
+	"solar-scope/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupDB(t *testing.T) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.IdempotencyKey{}); err != nil {
+		t.Fatal(err)
+	}
+	database.DB = db
+}
+
+// newApp, her çağrıda sayacı artıran ve status ile yanıt veren bir uygulama kurar.
+func newApp(calls *int, status int) *fiber.App {
+	app := fiber.New()
+	app.Use(auth.Authenticate(config.Config{}))
+	app.Post("/run", Middleware(time.Hour, time.Minute), func(c *fiber.Ctx) error {
+		*calls++
+		return c.Status(status).JSON(fiber.Map{"call": *calls, "provider": c.Query("provider")})
+	})
+	return app
+}
+
+func send(t *testing.T, app *fiber.App, target, key, body string) (*http.Response, string) {
+	t.Helper()
+	req := httptest.NewRequest(fiber.MethodPost, target, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set(Header, key)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, _ := io.ReadAll(resp.Body)
+	return resp, string(payload)
+}
+
+func TestReplay(t *testing.T) {
+	setupDB(t)
+	calls := 0
+	app := newApp(&calls, fiber.StatusCreated)
+
+	first, firstBody := send(t, app, "/run?provider=ml&snapshot=true", "k1", `{"a":1}`)
+	if first.StatusCode != fiber.StatusCreated || calls != 1 {
+		t.Fatalf("first request: status %d, calls %d", first.StatusCode, calls)
+	}
+
+	// Sorgu parametrelerinin sırası özeti değiştirmez
+	replay, replayBody := send(t, app, "/run?snapshot=true&provider=ml", "k1", `{"a":1}`)
+	if replay.StatusCode != fiber.StatusCreated || calls != 1 {
+		t.Fatalf("replay: status %d, calls %d", replay.StatusCode, calls)
+	}
+	if replay.Header.Get(HeaderReplayed) != "true" || replayBody != firstBody {
+		t.Errorf("replay: header %q, body %s, want %s", replay.Header.Get(HeaderReplayed), replayBody, firstBody)
+	}
+	if got := replay.Header.Get(fiber.HeaderContentType); !strings.HasPrefix(got, fiber.MIMEApplicationJSON) {
+		t.Errorf("replay content type = %q", got)
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	setupDB(t)
+	calls := 0
+	app := newApp(&calls, fiber.StatusOK)
+	send(t, app, "/run?provider=ml", "k1", `{"a":1}`)
+
+	tests := []struct {
+		name   string
+		target string
+		body   string
+	}{
+		{"different query", "/run?provider=baseline", `{"a":1}`},
+		{"missing query", "/run", `{"a":1}`},
+		{"different body", "/run?provider=ml", `{"a":2}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, _ := send(t, app, tt.target, "k1", tt.body)
+			if resp.StatusCode != fiber.StatusConflict {
+				t.Errorf("status = %d, want 409", resp.StatusCode)
+			}
+		})
+	}
+	if calls != 1 {
+		t.Errorf("handler called %d times, want 1", calls)
+	}
+}
+
+func TestKeyHeader(t *testing.T) {
+	setupDB(t)
+	calls := 0
+	app := newApp(&calls, fiber.StatusOK)
+	send(t, app, "/run", "", `{}`)
+	send(t, app, "/run", "", `{}`)
+	if calls != 2 {
+		t.Errorf("requests without a key: handler called %d times, want 2", calls)
+	}
+
+	resp, _ := send(t, app, "/run", strings.Repeat("k", maxKeyLength+1), `{}`)
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("long key: status = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestServerErrorsAreNotStored(t *testing.T) {
+	setupDB(t)
+	calls := 0
+	app := newApp(&calls, fiber.StatusBadGateway)
+	send(t, app, "/run", "k1", `{}`)
+	resp, _ := send(t, app, "/run", "k1", `{}`)
+	if calls != 2 || resp.Header.Get(HeaderReplayed) != "" {
+		t.Errorf("5xx response was replayed: calls %d", calls)
+	}
+}
+
+func TestAbandonedKeyIsTakenOver(t *testing.T) {
+	setupDB(t)
+	stale := models.IdempotencyKey{TenantID: 0, Key: "k1", RequestHash: "other", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := database.DB.Create(&stale).Error; err != nil {
+		t.Fatal(err)
+	}
+	database.DB.Model(&stale).Update("created_at", time.Now().Add(-time.Hour))
+
+	calls := 0
+	app := newApp(&calls, fiber.StatusOK)
+	resp, _ := send(t, app, "/run", "k1", `{}`)
+	if resp.StatusCode != fiber.StatusOK || calls != 1 {
+		t.Errorf("abandoned key: status %d, calls %d", resp.StatusCode, calls)
+	}
+}
+
+func TestAbandonedKeyTakeOverIsAtomic(t *testing.T) {
+	setupDB(t)
+	stale := models.IdempotencyKey{TenantID: 0, Key: "k1", RequestHash: "other", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := database.DB.Create(&stale).Error; err != nil {
+		t.Fatal(err)
+	}
+	database.DB.Model(&stale).Update("created_at", time.Now().Add(-time.Hour))
+
+	ctx := context.Background()
+	lockedBefore := time.Now().Add(-time.Minute)
+	first, err := database.TakeOverIdempotencyKey(ctx, 0, "k1", "a", time.Hour, lockedBefore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := database.TakeOverIdempotencyKey(ctx, 0, "k1", "b", time.Hour, lockedBefore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first || second {
+		t.Errorf("takeovers = %v, %v, want only the first to succeed", first, second)
+	}
+
+	// Yeni sahibin kilidi sürerken aynı anahtar çakışma döner
+	calls := 0
+	app := newApp(&calls, fiber.StatusOK)
+	resp, _ := send(t, app, "/run", "k1", `{}`)
+	if resp.StatusCode != fiber.StatusConflict || calls != 0 {
+		t.Errorf("key held by the new owner: status %d, calls %d", resp.StatusCode, calls)
+	}
+
+	// Tamamlanmış bir anahtar devralınamaz
+	if err := database.CompleteIdempotencyKey(ctx, 0, "k1", fiber.StatusOK, "application/json", []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	took, err := database.TakeOverIdempotencyKey(ctx, 0, "k1", "c", time.Hour, time.Now().Add(time.Minute))
+	if err != nil || took {
+		t.Errorf("completed key taken over: %v, %v", took, err)
+	}
+}
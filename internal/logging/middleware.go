@@ -0,0 +1,52 @@
+package logging
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// Middleware, her isteğe bir istek kimliği atar (gelen X-Request-ID başlığı
+// varsa onu kullanır), kimliği UserContext'e ve yanıt başlığına ekler ve
+// istek bitince bir erişim logu yazar.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		id := c.Get(HeaderRequestID)
+		if id == "" || len(id) > 128 {
+			id = utils.UUIDv4()
+		}
+		c.Set(HeaderRequestID, id)
+		ctx := WithRequestID(c.UserContext(), id)
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+		if err != nil {
+			// Hata işleyicisini şimdi çağır ki loglanan durum kodu doğru olsun
+			if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+				c.Status(fiber.StatusInternalServerError)
+			}
+		}
+
+		status := c.Response().StatusCode()
+		level := slog.LevelInfo
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= fiber.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		slog.Log(ctx, level, "request completed",
+			"method", c.Method(),
+			"path", c.Path(),
+			"route", c.Route().Path,
+			"status", status,
+			"latency_ms", float64(time.Since(start).Microseconds())/1000,
+			"ip", c.IP(),
+		)
+		return nil
+	}
+}
@@ -0,0 +1,173 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "solar_scope"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests handled, by route and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency, by route.",
+		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"method", "route"})
+
+	forecasterDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "forecaster_request_duration_seconds",
+		Help:      "SolarForecaster call latency, by endpoint.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 40, 60},
+	}, []string{"endpoint"})
+
+	forecasterErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "forecaster_request_errors_total",
+		Help:      "Failed SolarForecaster calls, by endpoint.",
+	}, []string{"endpoint"})
+
+	forecasterRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "forecaster_retries_total",
+		Help:      "SolarForecaster call retries, by endpoint.",
+	}, []string{"endpoint"})
+
+	forecasterCircuitState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "forecaster_circuit_state",
+		Help:      "SolarForecaster circuit breaker state per backend: 0 closed, 1 half-open, 2 open.",
+	}, []string{"backend"})
+
+	forecasterBackendUp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "forecaster_backend_up",
+		Help:      "Whether the last health probe of a SolarForecaster backend succeeded.",
+	}, []string{"backend"})
+
+	forecasterBackendInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "forecaster_backend_in_flight",
+		Help:      "SolarForecaster requests currently in flight, by backend.",
+	}, []string{"backend"})
+
+	vmQueryDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "victoriametrics_query_duration_seconds",
+		Help:      "VictoriaMetrics query latency.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	vmQueryFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "victoriametrics_query_failures_total",
+		Help:      "Failed VictoriaMetrics queries.",
+	})
+
+	forecastSaves = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "forecast_saves_total",
+		Help:      "Background forecast saves, by result (success or failure).",
+	}, []string{"result"})
+
+	forecastSavesInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "forecast_saves_in_flight",
+		Help:      "Background forecast saves currently running.",
+	})
+)
+
+// Handler, Prometheus formatındaki metrikleri sunar.
+func Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.Handler())
+}
+
+// Middleware, her isteğin sayısını ve süresini rota şablonuna göre kaydeder.
+// Ham yol yerine rota şablonu kullanılır ki etiket sayısı sınırlı kalsın.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			if fiberErr, ok := err.(*fiber.Error); ok {
+				status = fiberErr.Code
+			} else {
+				status = fiber.StatusInternalServerError
+			}
+		}
+		route := c.Route().Path
+		httpRequests.WithLabelValues(c.Method(), route, strconv.Itoa(status)).Inc()
+		httpDuration.WithLabelValues(c.Method(), route).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// ObserveForecasterCall, bir SolarForecaster çağrısının süresini ve sonucunu kaydeder.
+func ObserveForecasterCall(endpoint string, duration time.Duration, err error) {
+	forecasterDuration.WithLabelValues(endpoint).Observe(duration.Seconds())
+	if err != nil {
+		forecasterErrors.WithLabelValues(endpoint).Inc()
+	}
+}
+
+// ForecasterRetry, bir SolarForecaster çağrısının yeniden denendiğini kaydeder.
+func ForecasterRetry(endpoint string) {
+	forecasterRetries.WithLabelValues(endpoint).Inc()
+}
+
+// SetForecasterCircuitState, bir backend'in devre kesici durumunu kaydeder.
+func SetForecasterCircuitState(backend string, state int) {
+	forecasterCircuitState.WithLabelValues(backend).Set(float64(state))
+}
+
+// SetForecasterBackendUp, bir backend'in yoklama sonucunu kaydeder.
+func SetForecasterBackendUp(backend string, up bool) {
+	value := 0.0
+	if up {
+		value = 1
+	}
+	forecasterBackendUp.WithLabelValues(backend).Set(value)
+}
+
+// AddForecasterBackendInFlight, bir backend'de süren istek sayısını delta
+// kadar değiştirir.
+func AddForecasterBackendInFlight(backend string, delta int) {
+	forecasterBackendInFlight.WithLabelValues(backend).Add(float64(delta))
+}
+
+// ObserveVMQuery, bir VictoriaMetrics sorgusunun süresini ve sonucunu kaydeder.
+func ObserveVMQuery(duration time.Duration, err error) {
+	vmQueryDuration.Observe(duration.Seconds())
+	if err != nil {
+		vmQueryFailures.Inc()
+	}
+}
+
+// SaveStarted, arka planda başlayan bir tahmin kaydını işaretler. Dönen
+// fonksiyon kayıt bitince sonucuyla çağrılmalıdır.
+func SaveStarted() func(success bool) {
+	forecastSavesInFlight.Inc()
+	return func(success bool) {
+		forecastSavesInFlight.Dec()
+		if success {
+			forecastSaves.WithLabelValues("success").Inc()
+		} else {
+			forecastSaves.WithLabelValues("failure").Inc()
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+//go:embed openapi.json
+var specJSON []byte
+
+//go:embed docs.html
+var docsHTML []byte
+
+// Spec, gömülü OpenAPI dokümanını döner.
+func Spec() []byte {
+	return specJSON
+}
+
+// SpecHandler, OpenAPI dokümanını JSON olarak sunar.
+func SpecHandler(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	return c.Status(fiber.StatusOK).Send(specJSON)
+}
+
+// DocsHandler, OpenAPI dokümanını gösteren etkileşimli arayüzü sunar.
+func DocsHandler(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.Status(fiber.StatusOK).Send(docsHTML)
+}
+
+// Verify, uygulamada kayıtlı olup spec'te karşılığı olmayan rotaları döner.
+// Fiber'ın otomatik eklediği HEAD rotaları göz ardı edilir.
+func Verify(routes []fiber.Route) ([]string, error) {
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(specJSON, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+	}
+
+	seen := map[string]bool{}
+	var missing []string
+	for _, route := range routes {
+		if route.Method == fiber.MethodHead {
+			continue
+		}
+		path := specPath(route.Path)
+		key := route.Method + " " + path
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		operations, ok := spec.Paths[path]
+		if !ok {
+			missing = append(missing, key)
+			continue
+		}
+		if _, ok := operations[strings.ToLower(route.Method)]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// specPath, Fiber rota yolunu OpenAPI yol biçimine çevirir (/x/:id -> /x/{id}).
+func specPath(path string) string {
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + strings.TrimSuffix(strings.TrimPrefix(segment, ":"), "?") + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,53 @@
+package openapi
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestSpecIsValidJSON(t *testing.T) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(Spec(), &doc); err != nil {
+		t.Fatalf("openapi.json: %v", err)
+	}
+	if doc["openapi"] == nil || doc["paths"] == nil {
+		t.Error("spec has no openapi version or paths")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	routes := []fiber.Route{
+		{Method: fiber.MethodGet, Path: "/api/v1/forecasts/:id"},
+		{Method: fiber.MethodHead, Path: "/api/v1/forecasts/:id"},
+		{Method: fiber.MethodGet, Path: "/api/v1/sites/"},
+		{Method: fiber.MethodPatch, Path: "/api/v1/sites/:id"},
+		{Method: fiber.MethodGet, Path: "/api/v1/undocumented"},
+		{Method: fiber.MethodGet, Path: "/api/v1/undocumented"},
+	}
+	missing, err := Verify(routes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"GET /api/v1/undocumented", "PATCH /api/v1/sites/{id}"}
+	if !slices.Equal(missing, want) {
+		t.Errorf("Verify() = %v, want %v", missing, want)
+	}
+}
+
+func TestSpecPath(t *testing.T) {
+	tests := map[string]string{
+		"/":                              "/",
+		"/api/v1/sites/":                 "/api/v1/sites",
+		"/api/v1/sites/:id":              "/api/v1/sites/{id}",
+		"/api/v1/sessions/:session_id?":  "/api/v1/sessions/{session_id}",
+		"/api/v1/snapshots/:id/download": "/api/v1/snapshots/{id}/download",
+	}
+	for in, want := range tests {
+		if got := specPath(in); got != want {
+			t.Errorf("specPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"solar-scope/internal/baseline"
+)
+
+// Baseline, üretim geçmişinden tahmin yapan yerleşik sağlayıcıdır.
+type Baseline struct {
+	Forecaster baseline.Forecaster
+	// Reason, sonucun neden baseline olduğunu açıklar; boşsa on_demand.
+	Reason string
+}
+
+func (Baseline) Name() string { return NameBaseline }
+
+func (b Baseline) Forecast(ctx context.Context, req Request) (map[string]interface{}, error) {
+	if req.VM == nil {
+		return nil, errors.New("baseline provider needs a VictoriaMetrics client scoped to the tenant")
+	}
+	reason := b.Reason
+	if reason == "" {
+		reason = baseline.ReasonOnDemand
+	}
+	return b.Forecaster.Run(ctx, req.VM, req.Params, reason, req.SessionID)
+}
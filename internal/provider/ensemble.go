@@ -0,0 +1,171 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Member, topluluktaki bir sağlayıcı ve ağırlığıdır.
+type Member struct {
+	Provider Provider
+	Weight   float64
+}
+
+// MemberResult, bir üyenin topluluk sonucuna katkısıdır.
+type MemberResult struct {
+	Provider string  `json:"provider"`
+	Weight   float64 `json:"weight"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// Ensemble, üyelerin sonuçlarını ağırlıklarıyla birleştirir. Enerji
+// dengesi ve batarya özetindeki sayılar ağırlıklı ortalama, evet/hayır
+// alanları ağırlıklı çoğunluk olur; metin alanları ve öneriler en ağır
+// başarılı üyeden alınır. Başarısız üyeler atlanır ve ağırlıklar kalanlara
+// göre normalize edilir; hepsi başarısız olursa hata döner.
+type Ensemble struct {
+	Members []Member
+}
+
+func (Ensemble) Name() string { return NameEnsemble }
+
+// ParseWeights, "ml=0.7,baseline=0.3" biçimindeki ağırlıkları okur.
+func ParseWeights(value string) (map[string]float64, error) {
+	weights := map[string]float64{}
+	for _, part := range strings.Split(value, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		name, raw, ok := strings.Cut(part, "=")
+		weight, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if !ok || err != nil || weight < 0 {
+			return nil, fmt.Errorf("invalid ensemble weight %q, expected name=weight", part)
+		}
+		weights[strings.TrimSpace(name)] = weight
+	}
+	return weights, nil
+}
+
+// NewEnsemble, ağırlığı sıfırdan büyük sağlayıcılardan topluluk oluşturur.
+func NewEnsemble(registry Registry, weights map[string]float64) (Ensemble, error) {
+	var ensemble Ensemble
+	for _, name := range registry.Names() {
+		weight, ok := weights[name]
+		if !ok || weight == 0 {
+			continue
+		}
+		ensemble.Members = append(ensemble.Members, Member{Provider: registry[name], Weight: weight})
+	}
+	for name := range weights {
+		if !registry.Has(name) {
+			return Ensemble{}, fmt.Errorf("ensemble weight for unknown provider %q", name)
+		}
+		if name == NameEnsemble {
+			return Ensemble{}, errors.New("an ensemble cannot contain itself")
+		}
+	}
+	if len(ensemble.Members) == 0 {
+		return Ensemble{}, errors.New("ensemble needs at least one provider with a positive weight")
+	}
+	return ensemble, nil
+}
+
+func (e Ensemble) Forecast(ctx context.Context, req Request) (map[string]interface{}, error) {
+	results := make([]map[string]interface{}, len(e.Members))
+	errs := make([]error, len(e.Members))
+	report := make([]MemberResult, len(e.Members))
+	var wg sync.WaitGroup
+	for i, member := range e.Members {
+		report[i] = MemberResult{Provider: member.Provider.Name(), Weight: member.Weight}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := member.Provider.Forecast(ctx, req)
+			if err != nil {
+				report[i].Error = err.Error()
+				errs[i] = fmt.Errorf("%s: %w", member.Provider.Name(), err)
+				return
+			}
+			results[i] = result
+		}()
+	}
+	wg.Wait()
+
+	top := -1
+	for i, result := range results {
+		if result == nil {
+			continue
+		}
+		if top < 0 || e.Members[i].Weight > e.Members[top].Weight {
+			top = i
+		}
+	}
+	if top < 0 {
+		return nil, fmt.Errorf("all ensemble members failed: %w", errors.Join(errs...))
+	}
+
+	combined := copyMap(results[top])
+	for _, name := range []string{"energy_balance", "battery_performance"} {
+		target := section(combined, name)
+		if target == nil {
+			continue
+		}
+		for key, value := range target {
+			// Alanı içermeyen üyeler o alanın ortalamasına katılmaz
+			var sum, weight float64
+			for i, r := range results {
+				if r == nil {
+					continue
+				}
+				switch v := section(r, name)[key].(type) {
+				case float64:
+					sum += v * e.Members[i].Weight
+					weight += e.Members[i].Weight
+				case bool:
+					if v {
+						sum += e.Members[i].Weight
+					}
+					weight += e.Members[i].Weight
+				}
+			}
+			if weight == 0 {
+				continue
+			}
+			switch value.(type) {
+			case float64:
+				target[key] = math.Round(sum/weight*100) / 100
+			case bool:
+				target[key] = sum/weight >= 0.5
+			}
+		}
+	}
+	combined["general_status"] = NameEnsemble
+	combined["ensemble"] = map[string]interface{}{"members": report}
+	delete(combined, "baseline")
+	return combined, nil
+}
+
+// section, sonucun result nesnesindeki alt nesneyi döner; yoksa nil.
+func section(m map[string]interface{}, name string) map[string]interface{} {
+	result, _ := m["result"].(map[string]interface{})
+	nested, _ := result[name].(map[string]interface{})
+	return nested
+}
+
+// copyMap, sonucun result ve alt nesnelerini kopyalar; üyelerin sonuçları
+// değiştirilmez.
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if nested, ok := v.(map[string]interface{}); ok {
+			v = copyMap(nested)
+		}
+		out[k] = v
+	}
+	return out
+}
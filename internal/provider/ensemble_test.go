@@ -0,0 +1,154 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// stub, sabit bir sonuç veya hata dönen sağlayıcıdır.
+type stub struct {
+	name   string
+	result map[string]interface{}
+	err    error
+}
+
+func (s stub) Name() string { return s.name }
+
+func (s stub) Forecast(context.Context, Request) (map[string]interface{}, error) {
+	if s.err != nil || s.result == nil {
+		return nil, s.err
+	}
+	return copyMap(s.result), nil
+}
+
+// payload, verilen özet değerleriyle bir ForecastPayload üretir.
+func payload(status string, production float64, full bool, recommendation string) map[string]interface{} {
+	return map[string]interface{}{
+		"session_id":     status,
+		"general_status": status,
+		"result": map[string]interface{}{
+			"action_recommendations": []interface{}{recommendation},
+			"energy_balance": map[string]interface{}{
+				"total_production_kwh": production,
+				"status_description":   status,
+			},
+			"battery_performance": map[string]interface{}{
+				"full_charge_expected": full,
+			},
+		},
+		"baseline": map[string]interface{}{"method": "persistence"},
+	}
+}
+
+func TestEnsembleWeighting(t *testing.T) {
+	ml := stub{name: NameML, result: payload("ml", 10, true, "charge")}
+	base := stub{name: NameBaseline, result: payload("baseline", 4, false, "wait")}
+	ensemble := Ensemble{Members: []Member{{ml, 0.75}, {base, 0.25}}}
+
+	result, err := ensemble.Forecast(context.Background(), Request{})
+	if err != nil {
+		t.Fatalf("Forecast() error = %v", err)
+	}
+	balance := section(result, "energy_balance")
+	if balance["total_production_kwh"] != 8.5 {
+		t.Errorf("total_production_kwh = %v, want 8.5", balance["total_production_kwh"])
+	}
+	if balance["status_description"] != "ml" {
+		t.Errorf("status_description = %v, want the heaviest member's text", balance["status_description"])
+	}
+	if section(result, "battery_performance")["full_charge_expected"] != true {
+		t.Error("full_charge_expected should follow the weighted majority")
+	}
+	if result["general_status"] != NameEnsemble || result["baseline"] != nil {
+		t.Errorf("Forecast() = %v", result)
+	}
+	want := []MemberResult{{Provider: NameML, Weight: 0.75}, {Provider: NameBaseline, Weight: 0.25}}
+	if got := result["ensemble"].(map[string]interface{})["members"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("members = %v, want %v", got, want)
+	}
+	if section(ml.result, "energy_balance")["total_production_kwh"] != 10.0 {
+		t.Error("Forecast() modified a member's result")
+	}
+}
+
+func TestEnsembleSkipsFailedMembers(t *testing.T) {
+	ml := stub{name: NameML, err: errors.New("forecaster down")}
+	base := stub{name: NameBaseline, result: payload("baseline", 4, false, "wait")}
+	file := stub{name: NameFile, result: payload("file", 6, true, "sell")}
+	ensemble := Ensemble{Members: []Member{{ml, 0.6}, {base, 0.1}, {file, 0.3}}}
+
+	result, err := ensemble.Forecast(context.Background(), Request{})
+	if err != nil {
+		t.Fatalf("Forecast() error = %v", err)
+	}
+	// Kalan ağırlıklar 0.1 ve 0.3: (4*0.1 + 6*0.3) / 0.4 = 5.5
+	if got := section(result, "energy_balance")["total_production_kwh"]; got != 5.5 {
+		t.Errorf("total_production_kwh = %v, want 5.5", got)
+	}
+	if got := section(result, "energy_balance")["status_description"]; got != "file" {
+		t.Errorf("status_description = %v, want the heaviest successful member's text", got)
+	}
+	members := result["ensemble"].(map[string]interface{})["members"].([]MemberResult)
+	if members[0].Error != "forecaster down" {
+		t.Errorf("members[0] = %+v, want the failure reported", members[0])
+	}
+}
+
+func TestEnsembleAllFail(t *testing.T) {
+	down := errors.New("down")
+	ensemble := Ensemble{Members: []Member{{stub{name: NameML, err: down}, 1}, {stub{name: NameFile, err: ErrInvalidFile}, 1}}}
+	_, err := ensemble.Forecast(context.Background(), Request{})
+	if !errors.Is(err, down) || !errors.Is(err, ErrInvalidFile) || !strings.Contains(err.Error(), "all ensemble members failed") {
+		t.Errorf("Forecast() error = %v", err)
+	}
+}
+
+func TestParseWeights(t *testing.T) {
+	got, err := ParseWeights(" ml = 0.7, baseline=0.3,,")
+	if want := map[string]float64{"ml": 0.7, "baseline": 0.3}; err != nil || !maps.Equal(got, want) {
+		t.Errorf("ParseWeights() = %v, %v, want %v", got, err, want)
+	}
+	for _, value := range []string{"ml", "ml=x", "ml=-1"} {
+		if _, err := ParseWeights(value); err == nil {
+			t.Errorf("ParseWeights(%q) succeeded", value)
+		}
+	}
+}
+
+func TestNewEnsemble(t *testing.T) {
+	registry := Registry{
+		NameML:       stub{name: NameML},
+		NameBaseline: stub{name: NameBaseline},
+		NameEnsemble: Ensemble{},
+	}
+	ensemble, err := NewEnsemble(registry, map[string]float64{NameML: 2, NameBaseline: 0})
+	if err != nil || len(ensemble.Members) != 1 || ensemble.Members[0].Provider.Name() != NameML {
+		t.Errorf("NewEnsemble() = %+v, %v, want only the positive weight", ensemble, err)
+	}
+	for _, weights := range []map[string]float64{
+		{"unknown": 1},
+		{NameEnsemble: 1, NameML: 1},
+		{NameML: 0},
+	} {
+		if _, err := NewEnsemble(registry, weights); err == nil {
+			t.Errorf("NewEnsemble(%v) succeeded", weights)
+		}
+	}
+}
+
+func TestForecastMarksProvider(t *testing.T) {
+	result, err := Forecast(context.Background(), stub{name: NameML, result: payload("ml", 1, false, "")}, Request{})
+	if err != nil || result["provider"] != NameML {
+		t.Errorf("Forecast() = %v, %v", result, err)
+	}
+	if _, err := Forecast(context.Background(), stub{name: NameML}, Request{}); err == nil {
+		t.Error("Forecast() with an empty result succeeded")
+	}
+	if _, err := (Registry{}).Get("nope"); err == nil {
+		t.Error("Get() for an unknown provider succeeded")
+	}
+}
@@ -0,0 +1,201 @@
+package provider
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"solar-scope/internal/baseline"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// File, dış bir sistemin dizine bıraktığı tahminleri okur. Her tenant'ın
+// dosyaları Dir altında tenant ID'siyle adlandırılmış dizindedir (sistem
+// çağrıları için 0). Dizindeki en yeni .json veya .csv dosyası kullanılır:
+//
+//   - .json dosyası ForecastPayload biçiminde bir tahmindir.
+//   - .csv dosyası "time,production_w" başlıklı, RFC 3339 zamanlı, eşit
+//     aralıklı bir üretim tahminidir; batarya isteğin parametreleriyle
+//     simüle edilir.
+//
+// Biçimi bozuk dosyalar ErrInvalidFile'ı saran bir hata döndürür.
+type File struct {
+	Dir string
+	// MaxAge, bu süreden eski dosyaların kullanılmamasını sağlar; 0 sınırsızdır.
+	MaxAge time.Duration
+}
+
+// ErrInvalidFile, tahmin dosyası okunabildiği halde biçimi geçersiz
+// olduğunda döner; hata dosya adını ve sorunu içerir.
+var ErrInvalidFile = errors.New("invalid forecast file")
+
+func (File) Name() string { return NameFile }
+
+func (f File) Forecast(ctx context.Context, req Request) (map[string]interface{}, error) {
+	path, err := f.latest(req.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	sessionID := req.SessionID
+	if sessionID == "" {
+		sessionID = "file-" + strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return readCSV(path, req.Params, sessionID)
+	}
+	return readJSON(path, sessionID)
+}
+
+// latest, tenant'ın dizinindeki en yeni tahmin dosyasını bulur.
+func (f File) latest(tenantID uint) (string, error) {
+	dir := filepath.Join(f.Dir, strconv.FormatUint(uint64(tenantID), 10))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read forecast directory: %w", err)
+	}
+	var newest string
+	var newestTime time.Time
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".json" && ext != ".csv") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(newestTime) {
+			newest, newestTime = filepath.Join(dir, entry.Name()), info.ModTime()
+		}
+	}
+	if newest == "" {
+		return "", fmt.Errorf("no forecast files in %s", dir)
+	}
+	if f.MaxAge > 0 && time.Since(newestTime) > f.MaxAge {
+		return "", fmt.Errorf("newest forecast file %s is older than %s", filepath.Base(newest), f.MaxAge)
+	}
+	return newest, nil
+}
+
+// readJSON, ForecastPayload biçimindeki dosyayı okur. timestamp alanı
+// forecaster'ın biçiminde, baseline noktalarının zamanları RFC 3339
+// olmalıdır; aksi halde kayıt sırasında zaman ayrıştırılamaz.
+func readJSON(path, sessionID string) (map[string]interface{}, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, invalidFile(path, "%w", err)
+	}
+	if _, ok := result["result"].(map[string]interface{}); !ok {
+		return nil, invalidFile(path, "result object is missing")
+	}
+	if id, _ := result["session_id"].(string); id == "" {
+		result["session_id"] = sessionID
+	}
+	switch ts := result["timestamp"].(type) {
+	case nil:
+		result["timestamp"] = time.Now().Format(baseline.TimestampLayout)
+	case string:
+		if _, err := time.Parse(baseline.TimestampLayout, ts); err != nil {
+			return nil, invalidFile(path, "timestamp %q does not match the layout %s", ts, baseline.TimestampLayout)
+		}
+	default:
+		return nil, invalidFile(path, "timestamp must be a string")
+	}
+	if info, ok := result["baseline"].(map[string]interface{}); ok {
+		points, _ := info["points"].([]interface{})
+		for i, point := range points {
+			m, ok := point.(map[string]interface{})
+			if !ok {
+				return nil, invalidFile(path, "baseline point %d: must be an object", i+1)
+			}
+			at, _ := m["time"].(string)
+			if _, err := time.Parse(time.RFC3339, at); err != nil {
+				return nil, invalidFile(path, "baseline point %d: time must be an RFC 3339 timestamp", i+1)
+			}
+		}
+	}
+	return result, nil
+}
+
+// invalidFile, dosya adını ve sorunu içeren, ErrInvalidFile'ı saran bir hata
+// döner.
+func invalidFile(path, format string, args ...interface{}) error {
+	return fmt.Errorf("%w %s: %w", ErrInvalidFile, filepath.Base(path), fmt.Errorf(format, args...))
+}
+
+// readCSV, üretim tahminini okur ve şimdiden sonraki noktalar üzerinde
+// bataryayı simüle eder.
+func readCSV(path string, params baseline.Params, sessionID string) (map[string]interface{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, invalidFile(path, "%w", err)
+	}
+	timeCol, valueCol := -1, -1
+	for i, name := range header {
+		switch strings.TrimSpace(strings.ToLower(name)) {
+		case "time":
+			timeCol = i
+		case "production_w":
+			valueCol = i
+		}
+	}
+	if timeCol < 0 || valueCol < 0 {
+		return nil, invalidFile(path, "time and production_w columns are required")
+	}
+
+	now := time.Now()
+	var points []baseline.Point
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, invalidFile(path, "%w", err)
+		}
+		t, err := time.Parse(time.RFC3339, strings.TrimSpace(record[timeCol]))
+		if err != nil {
+			return nil, invalidFile(path, "line %d: invalid time", line)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(record[valueCol]), 64)
+		if err != nil {
+			return nil, invalidFile(path, "line %d: invalid production_w", line)
+		}
+		if t.After(now) {
+			points = append(points, baseline.Point{Time: t, Value: v})
+		}
+	}
+	if len(points) < 2 {
+		return nil, fmt.Errorf("forecast file %s has fewer than two future points", filepath.Base(path))
+	}
+	step := points[1].Time.Sub(points[0].Time)
+	if step <= 0 {
+		return nil, invalidFile(path, "times must be increasing")
+	}
+
+	from := points[0].Time.Add(-step)
+	result, _ := baseline.Simulate(points, from, step, params)
+	return baseline.ToMap(map[string]interface{}{
+		"session_id":     sessionID,
+		"timestamp":      from.Format(baseline.TimestampLayout),
+		"general_status": "external",
+		"result":         result,
+	}), nil
+}
@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"solar-scope/internal/baseline"
+	"solar-scope/internal/client"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFile, tenant'ın dizinine verilen değiştirilme zamanıyla bir dosya yazar.
+func writeFile(t *testing.T, dir string, tenantID, name, content string, modTime time.Time) {
+	t.Helper()
+	path := filepath.Join(dir, tenantID, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileJSON(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	writeFile(t, dir, "3", "old.json", `{"result": {}}`, now.Add(-time.Hour))
+	writeFile(t, dir, "3", "latest.json", `{"timestamp": "2025-01-01T12:00:00.123456", "result": {"date": "2025-01-01"}}`, now)
+	writeFile(t, dir, "3", "notes.txt", "ignored", now.Add(time.Minute))
+
+	result, err := Forecast(context.Background(), File{Dir: dir}, Request{TenantID: 3})
+	if err != nil {
+		t.Fatalf("Forecast() error = %v", err)
+	}
+	if result["session_id"] != "file-latest" || result["timestamp"] != "2025-01-01T12:00:00.123456" || result["provider"] != NameFile {
+		t.Errorf("Forecast() = %v", result)
+	}
+
+	result, err = File{Dir: dir}.Forecast(context.Background(), Request{TenantID: 3, SessionID: "s1"})
+	if err != nil || result["session_id"] != "s1" {
+		t.Errorf("Forecast() with session = %v, %v", result, err)
+	}
+}
+
+func TestFileJSONDefaultsTimestamp(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "0", "f.json", `{"result": {}}`, time.Now())
+	result, err := File{Dir: dir}.Forecast(context.Background(), Request{})
+	if err != nil {
+		t.Fatalf("Forecast() error = %v", err)
+	}
+	if _, err := time.Parse(baseline.TimestampLayout, result["timestamp"].(string)); err != nil {
+		t.Errorf("default timestamp %v does not match the layout: %v", result["timestamp"], err)
+	}
+}
+
+func TestFileInvalidJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"malformed", `{"result":`, "unexpected end of JSON input"},
+		{"missing result", `{"timestamp": "2025-01-01T12:00:00"}`, "result object is missing"},
+		{"rfc3339 timestamp", `{"timestamp": "2025-01-01T12:00:00Z", "result": {}}`, "does not match the layout"},
+		{"date only timestamp", `{"timestamp": "2025-01-01", "result": {}}`, "does not match the layout"},
+		{"numeric timestamp", `{"timestamp": 1735732800, "result": {}}`, "timestamp must be a string"},
+		{"point time", `{"result": {}, "baseline": {"points": [{"time": "2025-01-01T12:00:00Z"}, {"time": "2025-01-01 13:00"}]}}`, "baseline point 2"},
+		{"point not an object", `{"result": {}, "baseline": {"points": [1]}}`, "baseline point 1: must be an object"},
+		{"point without time", `{"result": {}, "baseline": {"points": [{}]}}`, "baseline point 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, "0", "bad.json", tt.content, time.Now())
+			_, err := File{Dir: dir}.Forecast(context.Background(), Request{})
+			if !errors.Is(err, ErrInvalidFile) {
+				t.Fatalf("Forecast() error = %v, want ErrInvalidFile", err)
+			}
+			if !strings.Contains(err.Error(), "bad.json") || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Forecast() error = %q, want the file name and %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileCSV(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().Add(time.Hour).Truncate(time.Hour).UTC()
+	var b strings.Builder
+	b.WriteString("time,production_w\n")
+	b.WriteString(start.Add(-2*time.Hour).Format(time.RFC3339) + ",900\n") // Geçmiş nokta atlanır
+	for i := 0; i < 3; i++ {
+		b.WriteString(start.Add(time.Duration(i)*time.Hour).Format(time.RFC3339) + ",1000\n")
+	}
+	writeFile(t, dir, "0", "plan.csv", b.String(), time.Now())
+
+	params := baseline.Params{BatteryParams: client.BatteryParams{BatteryCapacityWh: 10000, InitialSocPercent: 50}}
+	result, err := File{Dir: dir}.Forecast(context.Background(), Request{Params: params})
+	if err != nil {
+		t.Fatalf("Forecast() error = %v", err)
+	}
+	balance := section(result, "energy_balance")
+	if balance["total_production_kwh"] != 3.0 {
+		t.Errorf("total_production_kwh = %v, want 3", balance["total_production_kwh"])
+	}
+	if result["general_status"] != "external" || result["session_id"] != "file-plan" {
+		t.Errorf("Forecast() = %v", result)
+	}
+}
+
+func TestFileInvalidCSV(t *testing.T) {
+	future := time.Now().Add(time.Hour).UTC()
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"missing column", "time,value\n", "columns are required"},
+		{"invalid time", "time,production_w\n2025-01-01 12:00,1\n", "line 2: invalid time"},
+		{"invalid value", "time,production_w\n" + future.Format(time.RFC3339) + ",abc\n", "line 2: invalid production_w"},
+		{"decreasing", "time,production_w\n" + future.Add(time.Hour).Format(time.RFC3339) + ",1\n" + future.Format(time.RFC3339) + ",1\n", "times must be increasing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, "0", "bad.csv", tt.content, time.Now())
+			_, err := File{Dir: dir}.Forecast(context.Background(), Request{})
+			if !errors.Is(err, ErrInvalidFile) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Forecast() error = %v, want ErrInvalidFile with %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileLatest(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := (File{Dir: dir}).latest(1); err == nil {
+		t.Error("latest() for a missing directory succeeded")
+	}
+	writeFile(t, dir, "1", "notes.txt", "", time.Now())
+	if _, err := (File{Dir: dir}).latest(1); err == nil || errors.Is(err, ErrInvalidFile) {
+		t.Errorf("latest() without forecast files error = %v", err)
+	}
+	writeFile(t, dir, "1", "old.json", "{}", time.Now().Add(-2*time.Hour))
+	if _, err := (File{Dir: dir, MaxAge: time.Hour}).latest(1); err == nil {
+		t.Error("latest() returned a file older than MaxAge")
+	}
+	if path, err := (File{Dir: dir}).latest(1); err != nil || filepath.Base(path) != "old.json" {
+		t.Errorf("latest() = %q, %v", path, err)
+	}
+}
@@ -0,0 +1,27 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"solar-scope/internal/client"
+)
+
+// ML, Python SolarForecaster servisidir.
+type ML struct {
+	Client *client.SolarForecasterClient
+}
+
+func (ML) Name() string { return NameML }
+
+// Forecast, SessionID verilmişse session'ı, aksi halde /run gövdesini
+// forecaster'a gönderir.
+func (m ML) Forecast(ctx context.Context, req Request) (map[string]interface{}, error) {
+	switch {
+	case req.SessionID != "":
+		return m.Client.RunWithEnv(ctx, req.SessionID, req.Overrides)
+	case req.Run != nil:
+		return m.Client.RunForecast(ctx, *req.Run)
+	default:
+		return nil, errors.New("ml provider needs a run request or a session")
+	}
+}
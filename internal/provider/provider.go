@@ -0,0 +1,83 @@
+// Package provider, tahmin kaynaklarını ortak bir arayüzün arkasında toplar:
+// Python forecaster, yerleşik baseline, dizine bırakılan dosyalar ve
+// bunların ağırlıklı birleşimi.
+package provider
+
+import (
+	"context"
+	"fmt"
+	"solar-scope/internal/baseline"
+	"solar-scope/internal/client"
+	"sort"
+)
+
+// Sağlayıcı adları; kaydedilen tahminin provider alanında saklanır.
+const (
+	NameML       = "ml"
+	NameBaseline = "baseline"
+	NameFile     = "file"
+	NameEnsemble = "ensemble"
+)
+
+// Request, bir tahmin isteğinin sağlayıcılara göre girdileridir. Her
+// sağlayıcı ihtiyaç duyduğu alanları kullanır.
+type Request struct {
+	// Run, /run gövdesidir; SessionID boşsa ML sağlayıcısı bunu gönderir.
+	Run *client.RunRequest
+	// SessionID ve Overrides, run-with-env çağrısının girdileridir.
+	SessionID string
+	Overrides map[string]interface{}
+	// Params, batarya simülasyonu yapan sağlayıcıların ortak girdileridir.
+	Params baseline.Params
+	// VM, çağıranın tenant'ına göre daraltılmış VictoriaMetrics istemcisidir;
+	// tenant'ın etiketi yoksa nil olabilir.
+	VM       *client.PrometheusClient
+	TenantID uint
+}
+
+// Provider, ForecastPayload biçiminde tahmin üreten bir kaynaktır.
+type Provider interface {
+	Name() string
+	Forecast(ctx context.Context, req Request) (map[string]interface{}, error)
+}
+
+// Forecast, sağlayıcıyı çalıştırır ve sonucu üretenin adıyla işaretler.
+func Forecast(ctx context.Context, p Provider, req Request) (map[string]interface{}, error) {
+	result, err := p.Forecast(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, fmt.Errorf("%s provider returned an empty result", p.Name())
+	}
+	result["provider"] = p.Name()
+	return result, nil
+}
+
+// Registry, adı verilen sağlayıcıları tutar.
+type Registry map[string]Provider
+
+// Get, adı verilen sağlayıcıyı döner.
+func (r Registry) Get(name string) (Provider, error) {
+	p, ok := r[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown forecast provider %q, available: %v", name, r.Names())
+	}
+	return p, nil
+}
+
+// Names, kayıtlı sağlayıcıların adlarını sıralı döner.
+func (r Registry) Names() []string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Has, sağlayıcının kayıtlı olup olmadığını söyler.
+func (r Registry) Has(name string) bool {
+	_, ok := r[name]
+	return ok
+}
@@ -0,0 +1,53 @@
+package pv
+
+import (
+	"time"
+)
+
+// Point, bir adımdaki ölçülen ve beklenen güçtür.
+type Point struct {
+	Time      time.Time `json:"time"`
+	ActualW   *float64  `json:"actual_w"` // Ölçüm yoksa nil
+	ExpectedW float64   `json:"expected_w"`
+	POA       float64   `json:"poa"`
+}
+
+// Performance, bir penceredeki ölçülen ve beklenen enerjinin karşılaştırmasıdır.
+type Performance struct {
+	ActualWh   float64 `json:"actual_wh"`
+	ExpectedWh float64 `json:"expected_wh"`
+	// Ratio, ActualWh / ExpectedWh'tır; beklenen enerji yoksa nil.
+	Ratio *float64 `json:"ratio"`
+	// Coverage, ölçümü olan adımların oranıdır.
+	Coverage float64 `json:"coverage"`
+	Points   []Point `json:"points"`
+}
+
+// Summarize, adımların enerjisini toplayıp performans oranını hesaplar.
+// Ölçümü olmayan adımlar iki tarafa da katılmaz; veri eksikliği düşük
+// performans sayılmaz.
+func Summarize(points []Point, step time.Duration) Performance {
+	perf := Performance{Points: points}
+	if perf.Points == nil {
+		perf.Points = []Point{}
+	}
+	hours := step.Hours()
+	measured := 0
+	for _, p := range points {
+		if p.ActualW == nil {
+			continue
+		}
+		measured++
+		perf.ActualWh += *p.ActualW * hours
+		perf.ExpectedWh += p.ExpectedW * hours
+	}
+	perf.ActualWh, perf.ExpectedWh = round(perf.ActualWh), round(perf.ExpectedWh)
+	if len(points) > 0 {
+		perf.Coverage = round(float64(measured) / float64(len(points)))
+	}
+	if perf.ExpectedWh > 0 {
+		ratio := round(perf.ActualWh / perf.ExpectedWh)
+		perf.Ratio = &ratio
+	}
+	return perf
+}
@@ -0,0 +1,41 @@
+package pv
+
+import (
+	"testing"
+	"time"
+)
+
+func actual(w float64) *float64 { return &w }
+
+func TestSummarize(t *testing.T) {
+	start := time.Date(2025, 6, 21, 10, 0, 0, 0, time.UTC)
+	points := []Point{
+		{Time: start, ActualW: actual(1000), ExpectedW: 2000},
+		{Time: start.Add(30 * time.Minute), ExpectedW: 5000}, // Ölçüm yok, oranı düşürmez
+		{Time: start.Add(time.Hour), ActualW: actual(800), ExpectedW: 1200},
+	}
+	perf := Summarize(points, 30*time.Minute)
+
+	// Gerçekleşen (1000 + 800) * 0.5 = 900 Wh, beklenen (2000 + 1200) * 0.5 = 1600 Wh
+	if perf.ActualWh != 900 || perf.ExpectedWh != 1600 {
+		t.Errorf("Summarize() energy = %v / %v Wh, want 900 / 1600", perf.ActualWh, perf.ExpectedWh)
+	}
+	if perf.Ratio == nil || *perf.Ratio != 0.56 {
+		t.Errorf("Summarize() ratio = %v, want 0.56", perf.Ratio)
+	}
+	if perf.Coverage != 0.67 || len(perf.Points) != 3 {
+		t.Errorf("Summarize() coverage = %v with %d points, want 0.67 with 3", perf.Coverage, len(perf.Points))
+	}
+}
+
+func TestSummarizeWithoutExpectedEnergy(t *testing.T) {
+	night := Summarize([]Point{{ActualW: actual(0)}, {ActualW: actual(5)}}, time.Hour)
+	if night.Ratio != nil || night.Coverage != 1 || night.ActualWh != 5 {
+		t.Errorf("Summarize() at night = %+v, want no ratio", night)
+	}
+
+	empty := Summarize(nil, time.Hour)
+	if empty.Ratio != nil || empty.Coverage != 0 || empty.Points == nil {
+		t.Errorf("Summarize(nil) = %+v, want zero coverage and an empty point list", empty)
+	}
+}
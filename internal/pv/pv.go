@@ -0,0 +1,124 @@
+// Package pv, panel dizisinin geometrisinden ve ışınımdan beklenen gücü
+// PVWatts'a benzer basit bir modelle hesaplar: eğik düzleme düşen ışınım,
+// hücre sıcaklığına göre DC güç, sistem kayıpları ve sabit verimli, kırpmalı
+// bir invertör.
+package pv
+
+import (
+	"math"
+	"solar-scope/internal/solar"
+)
+
+// PVWatts varsayılanları.
+const (
+	DefaultTempCoefficient    = -0.37 // %/°C, standart modül
+	DefaultSystemLosses       = 14    // %
+	DefaultInverterEfficiency = 96    // %
+	DefaultDCACRatio          = 1.2
+	// albedo, zeminden yansıyan ışınım oranıdır.
+	albedo = 0.2
+	// noct, hücrenin 800 W/m² ve 20 °C ortamdaki sıcaklığıdır (°C).
+	noct = 45
+)
+
+// System, panel dizisinin özellikleridir. Sıfır değerli kayıp ve verim
+// alanları için PVWatts varsayılanları kullanılır.
+type System struct {
+	CapacityKWp float64
+	// Tilt yataydan, Azimuth kuzeyden saat yönünde derecedir (180 güney).
+	Tilt               float64
+	Azimuth            float64
+	TempCoefficient    float64 // %/°C
+	SystemLosses       float64 // %
+	InverterEfficiency float64 // %
+	DCACRatio          float64
+}
+
+// WithDefaults, verilmeyen alanları PVWatts varsayılanlarıyla doldurur.
+func (s System) WithDefaults() System {
+	if s.TempCoefficient == 0 {
+		s.TempCoefficient = DefaultTempCoefficient
+	}
+	if s.SystemLosses == 0 {
+		s.SystemLosses = DefaultSystemLosses
+	}
+	if s.InverterEfficiency == 0 {
+		s.InverterEfficiency = DefaultInverterEfficiency
+	}
+	if s.DCACRatio == 0 {
+		s.DCACRatio = DefaultDCACRatio
+	}
+	return s
+}
+
+// Estimate, bir andaki beklenen değerlerdir.
+type Estimate struct {
+	// POA, panel düzlemine düşen ışınımdır (W/m²).
+	POA       float64 `json:"poa"`
+	AOI       float64 `json:"angle_of_incidence"`
+	CellTempC float64 `json:"cell_temp_c"`
+	DCW       float64 `json:"dc_w"`
+	ACW       float64 `json:"ac_w"`
+}
+
+// Expected, güneşin konumu, ışınım ve ortam sıcaklığı için beklenen gücü
+// hesaplar.
+func Expected(s System, pos solar.Position, irr solar.Irradiance, ambientC float64) Estimate {
+	s = s.WithDefaults()
+	aoi := AngleOfIncidence(pos, s.Tilt, s.Azimuth)
+
+	// İzotropik gökyüzü: doğrudan + yaygın + zemin yansıması
+	tilt := rad(s.Tilt)
+	beam := 0.0
+	if pos.Elevation > 0 && aoi < 90 {
+		beam = irr.DNI * math.Cos(rad(aoi))
+	}
+	poa := beam + irr.DHI*(1+math.Cos(tilt))/2 + irr.GHI*albedo*(1-math.Cos(tilt))/2
+	poa = math.Max(0, poa)
+
+	cellTemp := ambientC + poa*(noct-20)/800
+	dc := s.CapacityKWp * 1000 * poa / 1000 * (1 + s.TempCoefficient/100*(cellTemp-25))
+	dc = math.Max(0, dc*(1-s.SystemLosses/100))
+	ac := math.Min(dc*s.InverterEfficiency/100, s.CapacityKWp*1000/s.DCACRatio)
+
+	return Estimate{
+		POA:       round(poa),
+		AOI:       round(aoi),
+		CellTempC: round(cellTemp),
+		DCW:       round(dc),
+		ACW:       round(ac),
+	}
+}
+
+// AngleOfIncidence, güneş ışınlarının panel normaliyle yaptığı açıdır.
+func AngleOfIncidence(pos solar.Position, tilt, azimuth float64) float64 {
+	zen, sunAz := rad(pos.Zenith), rad(pos.Azimuth)
+	cos := math.Cos(zen)*math.Cos(rad(tilt)) + math.Sin(zen)*math.Sin(rad(tilt))*math.Cos(sunAz-rad(azimuth))
+	return deg(math.Acos(math.Max(-1, math.Min(1, cos))))
+}
+
+// FromGHI, ölçülen yatay toplam ışınımı Erbs modeliyle doğrudan ve yaygın
+// bileşenlere ayırır.
+func FromGHI(pos solar.Position, ghi float64) solar.Irradiance {
+	cosZ := math.Cos(rad(pos.Zenith))
+	if ghi <= 0 || cosZ <= 0.0175 { // Güneş ufka çok yakınken bileşenler anlamsızlaşır
+		return solar.Irradiance{GHI: math.Max(0, ghi), DHI: math.Max(0, ghi)}
+	}
+	kt := math.Min(1, ghi/(solar.Extraterrestrial(pos.Time)*cosZ))
+	var fraction float64
+	switch {
+	case kt <= 0.22:
+		fraction = 1 - 0.09*kt
+	case kt <= 0.8:
+		fraction = 0.9511 - 0.1604*kt + 4.388*kt*kt - 16.638*math.Pow(kt, 3) + 12.336*math.Pow(kt, 4)
+	default:
+		fraction = 0.165
+	}
+	dhi := ghi * fraction
+	return solar.Irradiance{GHI: round(ghi), DNI: round((ghi - dhi) / cosZ), DHI: round(dhi)}
+}
+
+func rad(d float64) float64 { return d * math.Pi / 180 }
+func deg(r float64) float64 { return r * 180 / math.Pi }
+
+func round(v float64) float64 { return math.Round(v*100) / 100 }
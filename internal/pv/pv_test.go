@@ -0,0 +1,101 @@
+package pv
+
+import (
+	"math"
+	"solar-scope/internal/solar"
+	"testing"
+	"time"
+)
+
+var noon = time.Date(2025, 6, 21, 12, 0, 0, 0, time.UTC)
+
+func TestExpected(t *testing.T) {
+	// Yatay 5 kWp dizi, güneş tam tepede, 25 °C ortam:
+	// POA = 800 + 100 = 900, hücre 25 + 900*25/800 = 53.125 °C,
+	// DC = 5000 * 0.9 * (1 - 0.0037*28.125) * 0.86, AC = DC * 0.96
+	system := System{CapacityKWp: 5, Azimuth: 180}
+	pos := solar.Position{Time: noon, Elevation: 90, Zenith: 0, Azimuth: 180}
+	irr := solar.Irradiance{GHI: 900, DNI: 800, DHI: 100}
+
+	got := Expected(system, pos, irr, 25)
+	want := Estimate{POA: 900, AOI: 0, CellTempC: 53.13, DCW: 3467.28, ACW: 3328.59}
+	if got != want {
+		t.Errorf("Expected() = %+v, want %+v", got, want)
+	}
+
+	// DC/AC oranı 2 ise invertör 2500 W'ta kırpar
+	system.DCACRatio = 2
+	if got := Expected(system, pos, irr, 25); got.ACW != 2500 || got.DCW != want.DCW {
+		t.Errorf("Expected() with clipping = %+v, want AC clipped to 2500 W", got)
+	}
+}
+
+func TestExpectedTilted(t *testing.T) {
+	// Güneşe dik 30° eğimli panel doğrudan ışınımın tamamını alır; zemin
+	// yansıması da eklenir
+	system := System{CapacityKWp: 1, Tilt: 30, Azimuth: 180}
+	pos := solar.Position{Time: noon, Elevation: 60, Zenith: 30, Azimuth: 180}
+	irr := solar.Irradiance{GHI: 800, DNI: 800, DHI: 100}
+	got := Expected(system, pos, irr, 20)
+	c := math.Cos(30 * math.Pi / 180)
+	wantPOA := 800 + 100*(1+c)/2 + 800*albedo*(1-c)/2
+	if got.AOI != 0 || math.Abs(got.POA-wantPOA) > 0.01 {
+		t.Errorf("Expected() = %+v, want AOI 0 and POA %.2f", got, wantPOA)
+	}
+
+	// Güneş ufkun altındaysa yalnızca yaygın ışınım kalır
+	night := Expected(system, solar.Position{Time: noon, Elevation: -5, Zenith: 95}, solar.Irradiance{DHI: 10}, 20)
+	if want := 10 * (1 + c) / 2; math.Abs(night.POA-want) > 0.01 {
+		t.Errorf("Expected() below the horizon POA = %v, want %.2f", night.POA, want)
+	}
+	if dark := Expected(system, solar.Position{Time: noon, Elevation: -5, Zenith: 95}, solar.Irradiance{}, 20); dark.ACW != 0 || dark.DCW != 0 {
+		t.Errorf("Expected() in the dark = %+v, want zero power", dark)
+	}
+}
+
+func TestAngleOfIncidence(t *testing.T) {
+	tests := []struct {
+		zenith, azimuth, tilt, panelAzimuth, want float64
+	}{
+		{0, 180, 0, 180, 0},
+		{30, 180, 30, 180, 0},
+		{90, 90, 90, 90, 0},    // Doğuya bakan dikey panel, güneş doğuda ufukta
+		{90, 270, 90, 90, 180}, // Güneş batıda, panelin arkasında
+		{45, 180, 0, 0, 45},
+	}
+	for _, tt := range tests {
+		pos := solar.Position{Zenith: tt.zenith, Azimuth: tt.azimuth}
+		if got := AngleOfIncidence(pos, tt.tilt, tt.panelAzimuth); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("AngleOfIncidence(%+v) = %v, want %v", tt, got, tt.want)
+		}
+	}
+}
+
+func TestFromGHI(t *testing.T) {
+	pos := solar.Position{Time: noon, Zenith: 30}
+	cosZ := math.Cos(30 * math.Pi / 180)
+	for _, ghi := range []float64{50, 400, 800, 1000} {
+		irr := FromGHI(pos, ghi)
+		if irr.GHI != ghi || irr.DHI <= 0 || irr.DHI > ghi || math.Abs(irr.DNI*cosZ+irr.DHI-ghi) > 0.05 {
+			t.Errorf("FromGHI(%v) = %+v, want components adding up to GHI", ghi, irr)
+		}
+	}
+	// Açık havada (yüksek kt) yaygın pay düşük, kapalı havada yüksektir
+	if clear, cloudy := FromGHI(pos, 900), FromGHI(pos, 100); clear.DHI/clear.GHI >= cloudy.DHI/cloudy.GHI {
+		t.Errorf("diffuse fraction clear %+v, cloudy %+v", clear, cloudy)
+	}
+	if low := FromGHI(solar.Position{Time: noon, Zenith: 89.5}, 20); low.DNI != 0 || low.DHI != 20 {
+		t.Errorf("FromGHI() near the horizon = %+v, want all diffuse", low)
+	}
+	if none := FromGHI(pos, -3); none != (solar.Irradiance{}) {
+		t.Errorf("FromGHI(-3) = %+v, want zero", none)
+	}
+}
+
+func TestWithDefaults(t *testing.T) {
+	got := System{CapacityKWp: 3, SystemLosses: 10}.WithDefaults()
+	want := System{CapacityKWp: 3, TempCoefficient: DefaultTempCoefficient, SystemLosses: 10, InverterEfficiency: DefaultInverterEfficiency, DCACRatio: DefaultDCACRatio}
+	if got != want {
+		t.Errorf("WithDefaults() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,52 @@
+package quality
+
+import (
+	"context"
+	"solar-scope/internal/client"
+	"time"
+)
+
+// Ön kontrol modları.
+const (
+	ModeOff   = "off"   // Tahminlerden önce kontrol yapılmaz
+	ModeWarn  = "warn"  // Eşiğin altındaki tahminler çalışır, rapor yanıta eklenir
+	ModeBlock = "block" // Eşiğin altındaki tahminler reddedilir
+)
+
+// maxPoints, bir serinin aralık sorgusunda istenecek en fazla nokta sayısıdır;
+// uzun pencerelerde adım buna göre büyütülür.
+const maxPoints = 10000
+
+// Checker, eğitim penceresinin verisini VictoriaMetrics'ten alıp değerlendirir.
+type Checker struct {
+	Mode       string
+	Step       time.Duration
+	Thresholds Thresholds
+}
+
+// Enabled, tahminlerden önce kontrolün yapılıp yapılmayacağını söyler.
+func (c Checker) Enabled() bool {
+	return c.Mode == ModeWarn || c.Mode == ModeBlock
+}
+
+// Blocks, raporun tahmini engelleyip engellemediğini söyler.
+func (c Checker) Blocks(report Report) bool {
+	return c.Mode == ModeBlock && !report.Passed
+}
+
+// Run, metric'in son trainDays günlük verisini değerlendirir.
+func (c Checker) Run(ctx context.Context, pc *client.PrometheusClient, metric string, trainDays int) (Report, error) {
+	window := time.Duration(trainDays) * 24 * time.Hour
+	step := max(c.Step, time.Minute)
+	if minStep := (window / maxPoints).Round(time.Second); step < minStep {
+		step = minStep
+	}
+	end := time.Now().Truncate(step)
+	start := end.Add(-window)
+
+	matrix, err := pc.QueryRange(ctx, metric, start, end, step)
+	if err != nil {
+		return Report{}, err
+	}
+	return Analyze(metric, matrix, start, end, step, c.Thresholds), nil
+}
@@ -0,0 +1,261 @@
+package quality
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// maxListed, seri başına raporda ayrıntısı verilen en fazla aralık sayısıdır.
+const maxListed = 20
+
+// Thresholds, veri kalitesi analizinin ayarlarıdır.
+type Thresholds struct {
+	// MinScore, [0, 1] aralığındaki kalite puanının kabul sınırıdır.
+	MinScore float64
+	// MinGap, raporlanacak en kısa veri boşluğudur.
+	MinGap time.Duration
+	// FlatLine, sıfırdan farklı bir değerin aynı kaldığı bu süreden uzun
+	// aralıklar sensörün takıldığını gösterir. Gece boyunca sıfır kalan panel
+	// gücü olağan olduğundan sıfır değerler ancak 24 saati aşarsa sayılır.
+	FlatLine time.Duration
+	// MaxValue, fiziksel olarak mümkün en büyük değerdir; 0 ise denetlenmez.
+	MaxValue float64
+	// OutlierZ, medyandan sapmanın (MAD ile ölçeklenmiş) aykırı sayılacağı
+	// robust z-skorudur.
+	OutlierZ float64
+}
+
+// Interval, raporlanan bir boşluk veya sabit kalma aralığıdır.
+type Interval struct {
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	DurationSeconds int64     `json:"duration_seconds"`
+}
+
+// SeriesReport, tek bir serinin kalite özetidir.
+type SeriesReport struct {
+	Labels     map[string]string `json:"labels"`
+	Samples    int               `json:"samples"`
+	Expected   int               `json:"expected"`
+	Coverage   float64           `json:"coverage"`
+	GapCount   int               `json:"gap_count"`
+	Gaps       []Interval        `json:"gaps"`
+	FlatLines  []Interval        `json:"flat_lines"`
+	FlatPoints int               `json:"flat_points"`
+	Negative   int               `json:"negative"`
+	Impossible int               `json:"impossible"`
+	Outliers   int               `json:"outliers"`
+	Score      float64           `json:"score"`
+}
+
+// Report, eğitim penceresinin veri kalitesi raporudur.
+type Report struct {
+	Metric      string         `json:"metric"`
+	Start       time.Time      `json:"start"`
+	End         time.Time      `json:"end"`
+	StepSeconds int64          `json:"step_seconds"`
+	Score       float64        `json:"score"`
+	MinScore    float64        `json:"min_score"`
+	Passed      bool           `json:"passed"`
+	Issues      []string       `json:"issues"`
+	Series      []SeriesReport `json:"series"`
+}
+
+// Analyze, bir aralık sorgusunun sonucunu değerlendirir. Puan serilerin
+// ortalamasıdır; her serinin puanı kapsamadan sabit kalan, negatif, imkânsız
+// ve aykırı noktaların oranı düşülerek bulunur. Hiç seri yoksa puan 0'dır.
+func Analyze(metric string, matrix model.Matrix, start, end time.Time, step time.Duration, t Thresholds) Report {
+	report := Report{
+		Metric:      metric,
+		Start:       start,
+		End:         end,
+		StepSeconds: int64(step / time.Second),
+		MinScore:    t.MinScore,
+		Issues:      []string{},
+		Series:      []SeriesReport{},
+	}
+	expected := int(end.Sub(start)/step) + 1
+
+	var total float64
+	for _, stream := range matrix {
+		series := analyzeSeries(stream, start, end, step, expected, t)
+		total += series.Score
+		report.Series = append(report.Series, series)
+		report.Issues = append(report.Issues, describe(series)...)
+	}
+	if len(report.Series) == 0 {
+		report.Issues = append(report.Issues, "no samples in the training window")
+	} else {
+		report.Score = round(total / float64(len(report.Series)))
+	}
+	report.Passed = report.Score >= t.MinScore
+	return report
+}
+
+func analyzeSeries(stream *model.SampleStream, start, end time.Time, step time.Duration, expected int, t Thresholds) SeriesReport {
+	series := SeriesReport{
+		Labels:    map[string]string{},
+		Expected:  expected,
+		Gaps:      []Interval{},
+		FlatLines: []Interval{},
+	}
+	for name, value := range stream.Metric {
+		series.Labels[string(name)] = string(value)
+	}
+
+	var values []float64
+	var times []time.Time
+	for _, sample := range stream.Values {
+		v := float64(sample.Value)
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		values = append(values, v)
+		times = append(times, sample.Timestamp.Time())
+		if v < 0 {
+			series.Negative++
+		}
+		if t.MaxValue > 0 && v > t.MaxValue {
+			series.Impossible++
+		}
+	}
+	series.Samples = len(values)
+	if expected > 0 {
+		series.Coverage = round(math.Min(1, float64(series.Samples)/float64(expected)))
+	}
+
+	// Boşluklar: ardışık örnekler arasındaki ve pencerenin iki ucundaki eksikler
+	edges := slices.Concat([]time.Time{start.Add(-step)}, times, []time.Time{end.Add(step)})
+	for i := 1; i < len(edges); i++ {
+		missing := edges[i].Sub(edges[i-1]) - step
+		if missing < t.MinGap || missing <= 0 {
+			continue
+		}
+		series.GapCount++
+		if len(series.Gaps) < maxListed {
+			// Boşluk, ilk eksik noktadan bir sonraki örneğe (en fazla pencere sonuna) kadardır
+			series.Gaps = append(series.Gaps, interval(edges[i-1].Add(step), minTime(edges[i], end)))
+		}
+	}
+
+	// Sabit kalma: aynı değerin art arda tekrarlandığı uzun aralıklar. Bu
+	// noktalar aykırı değer taramasına girmez, aksi halde iki kez sayılırlar.
+	var rest []float64
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[i] {
+			j++
+		}
+		limit := t.FlatLine
+		if values[i] == 0 {
+			limit = max(limit, 24*time.Hour)
+		}
+		if limit > 0 && times[j].Sub(times[i]) > limit {
+			series.FlatPoints += j - i + 1
+			if len(series.FlatLines) < maxListed {
+				series.FlatLines = append(series.FlatLines, interval(times[i], times[j]))
+			}
+		} else {
+			rest = append(rest, values[i:j+1]...)
+		}
+		i = j + 1
+	}
+
+	series.Outliers = outliers(rest, t.OutlierZ)
+
+	score := series.Coverage
+	if expected > 0 {
+		bad := series.FlatPoints + series.Negative + series.Impossible + series.Outliers
+		score -= float64(bad) / float64(expected)
+	}
+	series.Score = round(math.Max(0, score))
+	return series
+}
+
+// outliers, sıfır olmayan değerler arasında medyandan robust z-skoru z'yi
+// aşan noktaları sayar. Gece sıfırları dağılımı bozmasın diye dışarıda
+// bırakılır.
+func outliers(values []float64, z float64) int {
+	if z <= 0 {
+		return 0
+	}
+	var nonZero []float64
+	for _, v := range values {
+		if v != 0 {
+			nonZero = append(nonZero, v)
+		}
+	}
+	if len(nonZero) < 3 {
+		return 0
+	}
+	med := median(nonZero)
+	deviations := make([]float64, len(nonZero))
+	for i, v := range nonZero {
+		deviations[i] = math.Abs(v - med)
+	}
+	mad := median(deviations) * 1.4826 // Normal dağılımda standart sapmaya denk
+	if mad == 0 {
+		return 0
+	}
+	count := 0
+	for _, v := range nonZero {
+		if math.Abs(v-med)/mad > z {
+			count++
+		}
+	}
+	return count
+}
+
+func median(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// describe, serinin sorunlarını okunabilir cümlelere çevirir.
+func describe(s SeriesReport) []string {
+	name := model.LabelSet{}
+	for k, v := range s.Labels {
+		name[model.LabelName(k)] = model.LabelValue(v)
+	}
+	var issues []string
+	if s.Coverage < 1 {
+		issues = append(issues, fmt.Sprintf("%s: %.0f%% coverage, %d gaps", name, s.Coverage*100, s.GapCount))
+	}
+	if len(s.FlatLines) > 0 {
+		issues = append(issues, fmt.Sprintf("%s: flat-lined for %d samples", name, s.FlatPoints))
+	}
+	if s.Negative > 0 {
+		issues = append(issues, fmt.Sprintf("%s: %d negative values", name, s.Negative))
+	}
+	if s.Impossible > 0 {
+		issues = append(issues, fmt.Sprintf("%s: %d values above the physical maximum", name, s.Impossible))
+	}
+	if s.Outliers > 0 {
+		issues = append(issues, fmt.Sprintf("%s: %d outliers", name, s.Outliers))
+	}
+	return issues
+}
+
+func interval(start, end time.Time) Interval {
+	return Interval{Start: start, End: end, DurationSeconds: int64(end.Sub(start) / time.Second)}
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func round(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
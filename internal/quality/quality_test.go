@@ -0,0 +1,226 @@
+package quality
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+var (
+	start = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	nan   = math.NaN()
+)
+
+var thresholds = Thresholds{
+	MinScore: 0.5,
+	MinGap:   2 * time.Hour,
+	FlatLine: 3 * time.Hour,
+	MaxValue: 1000,
+	OutlierZ: 5,
+}
+
+// stream, start'tan itibaren saatlik örneklerden bir seri oluşturur. NaN
+// değerler analizde atlandığından eksik örnek yerine geçer.
+func stream(values ...float64) *model.SampleStream {
+	s := &model.SampleStream{Metric: model.Metric{"sensor": "panel gucu"}}
+	for i, v := range values {
+		s.Values = append(s.Values, model.SamplePair{
+			Timestamp: model.TimeFromUnixNano(start.Add(time.Duration(i) * time.Hour).UnixNano()),
+			Value:     model.SampleValue(v),
+		})
+	}
+	return s
+}
+
+// ramp, from'dan başlayıp birer artan n değer döner.
+func ramp(from float64, n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = from + float64(i)
+	}
+	return values
+}
+
+func repeat(v float64, n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = v
+	}
+	return values
+}
+
+func concat(parts ...[]float64) []float64 {
+	var values []float64
+	for _, p := range parts {
+		values = append(values, p...)
+	}
+	return values
+}
+
+func at(hours int) time.Time {
+	return start.Add(time.Duration(hours) * time.Hour)
+}
+
+func TestAnalyzeSeries(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   SeriesReport
+	}{
+		{
+			name:   "clean",
+			values: ramp(100, 10),
+			want:   SeriesReport{Samples: 10, Expected: 10, Coverage: 1, Score: 1},
+		},
+		{
+			name:   "gaps at both edges",
+			values: concat(repeat(nan, 3), ramp(1, 4), repeat(nan, 3)),
+			want: SeriesReport{
+				Samples: 4, Expected: 10, Coverage: 0.4, GapCount: 2, Score: 0.4,
+				Gaps: []Interval{interval(at(0), at(3)), interval(at(7), at(9))},
+			},
+		},
+		{
+			name:   "short gap ignored",
+			values: concat(ramp(1, 2), []float64{nan}, ramp(3, 2)),
+			want:   SeriesReport{Samples: 4, Expected: 5, Coverage: 0.8, Score: 0.8},
+		},
+		{
+			name:   "night zeros under 24h",
+			values: concat(repeat(0, 20), ramp(100, 10)),
+			want:   SeriesReport{Samples: 30, Expected: 30, Coverage: 1, Score: 1},
+		},
+		{
+			name:   "zeros over 24h",
+			values: repeat(0, 30),
+			want: SeriesReport{
+				Samples: 30, Expected: 30, Coverage: 1, FlatPoints: 30, Score: 0,
+				FlatLines: []Interval{interval(at(0), at(29))},
+			},
+		},
+		{
+			name:   "flat line not counted as outliers",
+			values: concat(ramp(100, 10), repeat(900, 6)),
+			want: SeriesReport{
+				Samples: 16, Expected: 16, Coverage: 1, FlatPoints: 6, Score: 0.625,
+				FlatLines: []Interval{interval(at(10), at(15))},
+			},
+		},
+		{
+			name:   "outlier",
+			values: concat(ramp(100, 10), []float64{900}),
+			want:   SeriesReport{Samples: 11, Expected: 11, Coverage: 1, Outliers: 1, Score: 0.909},
+		},
+		{
+			name:   "negative and impossible",
+			values: []float64{1, -2, 3, 2000, 4},
+			want: SeriesReport{
+				Samples: 5, Expected: 5, Coverage: 1, Negative: 1, Impossible: 1, Outliers: 1, Score: 0.4,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end := at(len(tt.values) - 1)
+			got := analyzeSeries(stream(tt.values...), start, end, time.Hour, len(tt.values), thresholds)
+
+			if got.Samples != tt.want.Samples || got.Expected != tt.want.Expected || got.Coverage != tt.want.Coverage {
+				t.Errorf("samples/expected/coverage = %d/%d/%v, want %d/%d/%v",
+					got.Samples, got.Expected, got.Coverage, tt.want.Samples, tt.want.Expected, tt.want.Coverage)
+			}
+			if got.GapCount != tt.want.GapCount || !equalIntervals(got.Gaps, tt.want.Gaps) {
+				t.Errorf("gaps = %d %v, want %d %v", got.GapCount, got.Gaps, tt.want.GapCount, tt.want.Gaps)
+			}
+			if got.FlatPoints != tt.want.FlatPoints || !equalIntervals(got.FlatLines, tt.want.FlatLines) {
+				t.Errorf("flat = %d %v, want %d %v", got.FlatPoints, got.FlatLines, tt.want.FlatPoints, tt.want.FlatLines)
+			}
+			if got.Negative != tt.want.Negative || got.Impossible != tt.want.Impossible || got.Outliers != tt.want.Outliers {
+				t.Errorf("negative/impossible/outliers = %d/%d/%d, want %d/%d/%d",
+					got.Negative, got.Impossible, got.Outliers, tt.want.Negative, tt.want.Impossible, tt.want.Outliers)
+			}
+			if got.Score != tt.want.Score {
+				t.Errorf("Score = %v, want %v", got.Score, tt.want.Score)
+			}
+			if got.Labels["sensor"] != "panel gucu" {
+				t.Errorf("Labels = %v", got.Labels)
+			}
+		})
+	}
+}
+
+func equalIntervals(a, b []Interval) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Start.Equal(b[i].Start) || !a[i].End.Equal(b[i].End) || a[i].DurationSeconds != b[i].DurationSeconds {
+			return false
+		}
+	}
+	return true
+}
+
+func TestOutliers(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		z      float64
+		want   int
+	}{
+		{"disabled", []float64{1, 2, 3, 1000}, 0, 0},
+		{"too few values", []float64{1, 1000}, 5, 0},
+		{"zero MAD", []float64{5, 5, 5, 5, 100}, 5, 0},
+		{"zeros ignored", concat(repeat(0, 20), ramp(10, 5)), 5, 0},
+		{"one outlier", concat(ramp(100, 10), []float64{900}), 5, 1},
+		{"both sides", concat(ramp(100, 10), []float64{900, 1}), 5, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := outliers(tt.values, tt.z); got != tt.want {
+				t.Errorf("outliers() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	end := at(9)
+
+	t.Run("empty matrix", func(t *testing.T) {
+		report := Analyze("panel", model.Matrix{}, start, end, time.Hour, thresholds)
+		if report.Score != 0 || report.Passed {
+			t.Errorf("Score = %v, Passed = %v, want 0 and false", report.Score, report.Passed)
+		}
+		if len(report.Issues) != 1 || !strings.Contains(report.Issues[0], "no samples") {
+			t.Errorf("Issues = %v", report.Issues)
+		}
+	})
+
+	t.Run("mean of series", func(t *testing.T) {
+		matrix := model.Matrix{
+			stream(ramp(100, 10)...),
+			stream(concat(repeat(nan, 3), ramp(1, 4), repeat(nan, 3))...),
+		}
+		report := Analyze("panel", matrix, start, end, time.Hour, thresholds)
+		if report.Score != 0.7 || !report.Passed {
+			t.Errorf("Score = %v, Passed = %v, want 0.7 and true", report.Score, report.Passed)
+		}
+		if report.StepSeconds != 3600 || len(report.Series) != 2 {
+			t.Errorf("StepSeconds = %d, Series = %d", report.StepSeconds, len(report.Series))
+		}
+		if len(report.Issues) != 1 || !strings.Contains(report.Issues[0], "40% coverage, 2 gaps") {
+			t.Errorf("Issues = %v", report.Issues)
+		}
+	})
+
+	t.Run("below min score", func(t *testing.T) {
+		matrix := model.Matrix{stream(repeat(0, 30)...)}
+		report := Analyze("panel", matrix, start, at(29), time.Hour, thresholds)
+		if report.Passed {
+			t.Errorf("Passed = true for score %v", report.Score)
+		}
+	})
+}
@@ -0,0 +1,15 @@
+//go:build !linux && !darwin
+
+package requestctx
+
+import "net"
+
+// supported, bu platformda bağlantı yoklaması desteklenmediği için false
+// döner; istekler yine de bittiklerinde ve zaman aşımlarında iptal edilir.
+func supported(net.Conn) bool {
+	return false
+}
+
+func peerClosed(net.Conn) bool {
+	return false
+}
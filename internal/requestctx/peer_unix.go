@@ -0,0 +1,43 @@
+//go:build linux || darwin
+
+package requestctx
+
+import (
+	"errors"
+	"net"
+	"syscall"
+)
+
+// supported, bağlantının yoklanabilir bir soket olup olmadığını söyler.
+func supported(conn net.Conn) bool {
+	_, ok := conn.(syscall.Conn)
+	return ok
+}
+
+// peerClosed, sokete veri tüketmeden (MSG_PEEK) ve beklemeden bakar. Okuma 0
+// bayt dönerse karşı taraf bağlantıyı kapatmıştır; bekleyen veri veya EAGAIN
+// bağlantının açık olduğunu gösterir.
+func peerClosed(conn net.Conn) bool {
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return false
+	}
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return false
+	}
+
+	closed := false
+	buf := make([]byte, 1)
+	raw.Read(func(fd uintptr) bool {
+		n, _, err := syscall.Recvfrom(int(fd), buf, syscall.MSG_PEEK|syscall.MSG_DONTWAIT)
+		switch {
+		case err == nil:
+			closed = n == 0
+		case errors.Is(err, syscall.ECONNRESET):
+			closed = true
+		}
+		return true // net paketinin okumayı beklemesine izin verme
+	})
+	return closed
+}
@@ -0,0 +1,53 @@
+package requestctx
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// pollInterval, istemci bağlantısının kontrol edilme aralığıdır.
+const pollInterval = 500 * time.Millisecond
+
+// ErrClientDisconnected, istemci yanıtı beklemeden bağlantıyı kapattığında
+// isteğin context'ine iptal nedeni olarak işlenir.
+var ErrClientDisconnected = errors.New("client disconnected")
+
+// Middleware, UserContext'i istek bitince veya istemci bağlantıyı kapatınca
+// iptal edilen bir context ile değiştirir. fasthttp kopan bağlantıları
+// bildirmediği için bağlantı handler çalışırken düzenli olarak yoklanır;
+// böylece VictoriaMetrics ve SolarForecaster çağrıları boşuna sürmez.
+// Handler döndükten sonra çalışacak işler context.WithoutCancel kullanmalıdır.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithCancelCause(c.UserContext())
+		defer cancel(nil)
+		c.SetUserContext(ctx)
+
+		if conn := c.Context().Conn(); conn != nil && supported(conn) {
+			done := make(chan struct{})
+			defer close(done)
+			go func() {
+				ticker := time.NewTicker(pollInterval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-done:
+						return
+					case <-ticker.C:
+						if peerClosed(conn) {
+							slog.InfoContext(ctx, "client disconnected, cancelling request")
+							cancel(ErrClientDisconnected)
+							return
+						}
+					}
+				}
+			}()
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,145 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"solar-scope/database"
+	"solar-scope/internal/client"
+	"time"
+)
+
+// Deleter, bir session'ı forecaster'dan siler.
+type Deleter func(ctx context.Context, sessionID string) error
+
+// ReapedSession, süresi dolduğu için silinen (veya dry-run'da silinecek olan)
+// bir session'dır.
+type ReapedSession struct {
+	SessionID    string    `json:"session_id"`
+	TenantID     uint      `json:"tenant_id"`
+	Owner        string    `json:"owner"`
+	LastActivity time.Time `json:"last_activity"`
+	TTLSeconds   int64     `json:"ttl_seconds"`
+}
+
+// ReapReport, bir temizlik turunun sonucudur.
+type ReapReport struct {
+	DryRun    bool            `json:"dry_run"`
+	Deleted   []ReapedSession `json:"deleted"`
+	Failed    []string        `json:"failed"`
+	CheckedAt time.Time       `json:"checked_at"`
+}
+
+// Reaper, TTL'i dolan kullanılmayan session'ları belirli aralıklarla
+// forecaster'dan ve yerel kayıtlardan siler.
+type Reaper struct {
+	deleteSession Deleter
+	defaultTTL    time.Duration
+	interval      time.Duration
+	dryRun        bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewReaper, temizliği arka planda başlatır. interval 0 ise yalnızca Reap ile
+// elle çalıştırılır. dryRun açıksa hiçbir şey silinmez, yalnızca loglanır.
+func NewReaper(deleteSession Deleter, defaultTTL, interval time.Duration, dryRun bool) *Reaper {
+	r := &Reaper{
+		deleteSession: deleteSession,
+		defaultTTL:    defaultTTL,
+		interval:      interval,
+		dryRun:        dryRun,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	if interval > 0 {
+		go r.loop()
+	} else {
+		close(r.done)
+	}
+	return r
+}
+
+// DryRun, arka plandaki temizliğin dry-run modunda olup olmadığını söyler.
+func (r *Reaper) DryRun() bool {
+	return r.dryRun
+}
+
+// Reap, süresi dolan session'ları hemen siler. Forecaster'da bulunamayan
+// session'lar zaten silinmiş sayılır ve yalnızca yerel kaydı kaldırılır.
+func (r *Reaper) Reap(ctx context.Context, dryRun bool) (ReapReport, error) {
+	now := time.Now()
+	report := ReapReport{DryRun: dryRun, Deleted: []ReapedSession{}, Failed: []string{}, CheckedAt: now}
+
+	expired, err := database.ExpiredSessions(ctx, r.defaultTTL, now)
+	if err != nil {
+		return report, err
+	}
+	for _, session := range expired {
+		reaped := ReapedSession{
+			SessionID:    session.SessionID,
+			TenantID:     session.TenantID,
+			Owner:        session.Owner,
+			LastActivity: session.LastActivity(),
+			TTLSeconds:   int64(session.TTL / time.Second),
+		}
+		logArgs := []any{
+			"session_id", reaped.SessionID, "tenant_id", reaped.TenantID,
+			"last_activity", reaped.LastActivity, "ttl", session.TTL.String(),
+		}
+		if dryRun {
+			slog.InfoContext(ctx, "expired forecaster session would be deleted (dry run)", logArgs...)
+			report.Deleted = append(report.Deleted, reaped)
+			continue
+		}
+
+		if err := r.deleteSession(ctx, session.SessionID); err != nil && !notFound(err) {
+			slog.ErrorContext(ctx, "error deleting expired forecaster session", append(logArgs, "error", err)...)
+			report.Failed = append(report.Failed, session.SessionID)
+			if ctx.Err() != nil {
+				return report, ctx.Err()
+			}
+			continue
+		}
+		if err := database.DeleteSessionRecord(session.SessionID); err != nil {
+			slog.ErrorContext(ctx, "error deleting expired session record", append(logArgs, "error", err)...)
+			report.Failed = append(report.Failed, session.SessionID)
+			continue
+		}
+		slog.InfoContext(ctx, "expired forecaster session deleted", logArgs...)
+		report.Deleted = append(report.Deleted, reaped)
+	}
+	return report, nil
+}
+
+// Close, arka plandaki temizliği durdurur.
+func (r *Reaper) Close() {
+	close(r.stop)
+	<-r.done
+}
+
+func (r *Reaper) loop() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), r.interval)
+			if _, err := r.Reap(ctx, r.dryRun); err != nil {
+				slog.Error("error reaping expired forecaster sessions", "error", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// notFound, session'ın forecaster'da zaten bulunmadığını söyler.
+func notFound(err error) bool {
+	var ue *client.UpstreamError
+	return errors.As(err, &ue) && ue.Status == http.StatusNotFound
+}
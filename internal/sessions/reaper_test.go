@@ -0,0 +1,160 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"solar-scope/database"
+	"solar-scope/internal/client"
+	"solar-scope/models"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupDB(t *testing.T) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Paylaşılan bellek içi veritabanı son bağlantı kapanınca silinir
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(&models.Tenant{}, &models.ForecasterSession{}); err != nil {
+		t.Fatal(err)
+	}
+	database.DB = db
+}
+
+// createSession, son etkinliği age kadar önce olan bir session kaydı ekler.
+func createSession(t *testing.T, sessionID string, age time.Duration) {
+	t.Helper()
+	session := models.ForecasterSession{SessionID: sessionID, SyncState: models.SessionSynced}
+	session.CreatedAt = time.Now().Add(-age)
+	if err := database.DB.Create(&session).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func sessionIDs(t *testing.T) map[string]bool {
+	t.Helper()
+	var sessions []models.ForecasterSession
+	if err := database.DB.Find(&sessions).Error; err != nil {
+		t.Fatal(err)
+	}
+	ids := make(map[string]bool, len(sessions))
+	for _, s := range sessions {
+		ids[s.SessionID] = true
+	}
+	return ids
+}
+
+// fakeForecaster, session ID başına yapılandırılan hatayı döner ve silme
+// çağrılarını kaydeder.
+type fakeForecaster struct {
+	errs    map[string]error
+	deleted []string
+}
+
+func (f *fakeForecaster) deleteSession(_ context.Context, sessionID string) error {
+	f.deleted = append(f.deleted, sessionID)
+	return f.errs[sessionID]
+}
+
+func TestReap(t *testing.T) {
+	setupDB(t)
+	createSession(t, "fresh", time.Minute)
+	createSession(t, "expired", 2*time.Hour)
+	createSession(t, "gone", 2*time.Hour)
+	createSession(t, "failing", 2*time.Hour)
+
+	forecaster := &fakeForecaster{errs: map[string]error{
+		"gone":    &client.UpstreamError{Status: http.StatusNotFound, Message: "session not found"},
+		"failing": &client.UpstreamError{Status: http.StatusBadGateway, Err: errors.New("bad gateway")},
+	}}
+	reaper := NewReaper(forecaster.deleteSession, time.Hour, 0, false)
+	defer reaper.Close()
+
+	report, err := reaper.Reap(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deleted := map[string]bool{}
+	for _, s := range report.Deleted {
+		deleted[s.SessionID] = true
+		if s.TTLSeconds != 3600 {
+			t.Errorf("%s TTLSeconds = %d, want 3600", s.SessionID, s.TTLSeconds)
+		}
+	}
+	if len(deleted) != 2 || !deleted["expired"] || !deleted["gone"] {
+		t.Errorf("Deleted = %v, want expired and gone", report.Deleted)
+	}
+	if len(report.Failed) != 1 || report.Failed[0] != "failing" {
+		t.Errorf("Failed = %v, want [failing]", report.Failed)
+	}
+	if len(forecaster.deleted) != 3 {
+		t.Errorf("forecaster delete calls = %v, want the three expired sessions", forecaster.deleted)
+	}
+
+	// 404 zaten silinmiş sayılır; 5xx'te yerel kayıt bir sonraki tur için kalır
+	remaining := sessionIDs(t)
+	if len(remaining) != 2 || !remaining["fresh"] || !remaining["failing"] {
+		t.Errorf("remaining records = %v, want fresh and failing", remaining)
+	}
+}
+
+func TestReapDryRun(t *testing.T) {
+	setupDB(t)
+	createSession(t, "fresh", time.Minute)
+	createSession(t, "expired", 2*time.Hour)
+
+	forecaster := &fakeForecaster{}
+	reaper := NewReaper(forecaster.deleteSession, time.Hour, 0, true)
+	defer reaper.Close()
+
+	report, err := reaper.Reap(context.Background(), reaper.DryRun())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.DryRun || len(report.Deleted) != 1 || report.Deleted[0].SessionID != "expired" {
+		t.Errorf("report = %+v, want expired listed as a dry run", report)
+	}
+	if len(forecaster.deleted) != 0 {
+		t.Errorf("forecaster delete calls = %v, want none", forecaster.deleted)
+	}
+	if remaining := sessionIDs(t); len(remaining) != 2 {
+		t.Errorf("remaining records = %v, want both", remaining)
+	}
+}
+
+func TestReapTenantTTL(t *testing.T) {
+	setupDB(t)
+	tenant := models.Tenant{Name: "acme", Slug: "acme", LabelName: "site", LabelValue: "acme", SessionTTLSeconds: int64((3 * time.Hour) / time.Second)}
+	if err := database.DB.Create(&tenant).Error; err != nil {
+		t.Fatal(err)
+	}
+	session := models.ForecasterSession{SessionID: "long-lived", TenantID: tenant.ID, SyncState: models.SessionSynced}
+	session.CreatedAt = time.Now().Add(-2 * time.Hour)
+	if err := database.DB.Create(&session).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	forecaster := &fakeForecaster{}
+	report, err := NewReaper(forecaster.deleteSession, time.Hour, 0, false).Reap(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Deleted) != 0 || len(forecaster.deleted) != 0 {
+		t.Errorf("Deleted = %v, want none within the tenant's TTL", report.Deleted)
+	}
+}
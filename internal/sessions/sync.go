@@ -0,0 +1,80 @@
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"solar-scope/database"
+	"time"
+)
+
+// Lister, upstream session listesini session ID -> backend olarak döner.
+type Lister func(ctx context.Context) (map[string]string, error)
+
+// Syncer, yerel session kayıtlarını belirli aralıklarla upstream listesiyle
+// senkronize eder.
+type Syncer struct {
+	list     Lister
+	interval time.Duration
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewSyncer, senkronizasyonu arka planda başlatır. interval 0 ise yalnızca
+// Sync ile elle çalıştırılır.
+func NewSyncer(list Lister, interval time.Duration) *Syncer {
+	s := &Syncer{
+		list:     list,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if interval > 0 {
+		go s.loop()
+	} else {
+		close(s.done)
+	}
+	return s
+}
+
+// Sync, senkronizasyonu hemen çalıştırır.
+func (s *Syncer) Sync(ctx context.Context) (database.SessionSyncReport, error) {
+	upstream, err := s.list(ctx)
+	if err != nil {
+		return database.SessionSyncReport{}, fmt.Errorf("failed to list upstream sessions: %w", err)
+	}
+	report, err := database.SyncSessions(ctx, upstream)
+	if err != nil {
+		return report, fmt.Errorf("failed to sync session records: %w", err)
+	}
+	if len(report.LocalOnly) > 0 || len(report.UpstreamOnly) > 0 {
+		slog.WarnContext(ctx, "session registry out of sync with forecaster",
+			"local_only", report.LocalOnly, "upstream_only", report.UpstreamOnly)
+	}
+	return report, nil
+}
+
+// Close, arka plandaki senkronizasyonu durdurur.
+func (s *Syncer) Close() {
+	close(s.stop)
+	<-s.done
+}
+
+func (s *Syncer) loop() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), s.interval)
+			if _, err := s.Sync(ctx); err != nil {
+				slog.Error("error syncing forecaster sessions", "error", err)
+			}
+			cancel()
+		}
+	}
+}
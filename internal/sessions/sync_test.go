@@ -0,0 +1,80 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"solar-scope/database"
+	"solar-scope/models"
+	"testing"
+	"time"
+)
+
+func TestSync(t *testing.T) {
+	setupDB(t)
+	createSession(t, "both", time.Minute)
+	createSession(t, "local", time.Minute)
+
+	list := func(context.Context) (map[string]string, error) {
+		return map[string]string{"both": "http://forecaster-a", "external": "http://forecaster-b"}, nil
+	}
+	report, err := NewSyncer(list, 0).Sync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Synced != 1 {
+		t.Errorf("Synced = %d, want 1", report.Synced)
+	}
+	if len(report.LocalOnly) != 1 || report.LocalOnly[0] != "local" {
+		t.Errorf("LocalOnly = %v, want [local]", report.LocalOnly)
+	}
+	if len(report.UpstreamOnly) != 1 || report.UpstreamOnly[0] != "external" {
+		t.Errorf("UpstreamOnly = %v, want [external]", report.UpstreamOnly)
+	}
+
+	var local models.ForecasterSession
+	if err := database.DB.Where("session_id = ?", "local").First(&local).Error; err != nil {
+		t.Fatal(err)
+	}
+	if local.SyncState != models.SessionLocalOnly {
+		t.Errorf("local SyncState = %q, want %q", local.SyncState, models.SessionLocalOnly)
+	}
+}
+
+func TestSyncListError(t *testing.T) {
+	setupDB(t)
+	createSession(t, "both", time.Minute)
+
+	upstreamErr := errors.New("connection refused")
+	list := func(context.Context) (map[string]string, error) { return nil, upstreamErr }
+	if _, err := NewSyncer(list, 0).Sync(context.Background()); !errors.Is(err, upstreamErr) {
+		t.Fatalf("Sync() error = %v, want %v", err, upstreamErr)
+	}
+
+	// Başarısız listeleme kayıtları local_only olarak işaretlememeli
+	var session models.ForecasterSession
+	if err := database.DB.Where("session_id = ?", "both").First(&session).Error; err != nil {
+		t.Fatal(err)
+	}
+	if session.SyncState != models.SessionSynced {
+		t.Errorf("SyncState = %q, want %q", session.SyncState, models.SessionSynced)
+	}
+}
+
+func TestSyncerClose(t *testing.T) {
+	setupDB(t)
+	calls := make(chan struct{}, 1)
+	list := func(context.Context) (map[string]string, error) {
+		select {
+		case calls <- struct{}{}:
+		default:
+		}
+		return map[string]string{}, nil
+	}
+	syncer := NewSyncer(list, 10*time.Millisecond)
+	select {
+	case <-calls:
+	case <-time.After(time.Second):
+		t.Fatal("background sync did not run")
+	}
+	syncer.Close()
+}
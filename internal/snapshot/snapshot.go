@@ -0,0 +1,127 @@
+package snapshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"solar-scope/internal/client"
+	"sort"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// Encoding, saklanan anlık görüntünün biçimidir.
+const Encoding = "application/json+gzip"
+
+// chunkPoints, tek bir aralık sorgusunda seri başına istenecek en fazla nokta
+// sayısıdır. VictoriaMetrics'in varsayılan sınırı 30000'dir; uzun pencereler
+// parçalara bölünerek sorgulanır.
+const chunkPoints = 10000
+
+// Series, bir serinin etiketleri ve [unix saniye, değer] çiftleridir.
+type Series struct {
+	Labels map[string]string `json:"labels"`
+	Values [][2]float64      `json:"values"`
+}
+
+// Data, eğitim penceresinin sıkıştırılmadan önceki içeriğidir.
+type Data struct {
+	Metric      string    `json:"metric"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	StepSeconds int64     `json:"step_seconds"`
+	Series      []Series  `json:"series"`
+}
+
+// Snapshot, sıkıştırılmış içerik ve özetidir. Hash sıkıştırılmamış JSON'un
+// SHA-256 özetidir; aynı veri her zaman aynı hash'i verir.
+type Snapshot struct {
+	Data             Data
+	Samples          int
+	Hash             string
+	UncompressedSize int
+	Compressed       []byte
+}
+
+// Capture, metric'in son trainDays günlük verisini step çözünürlüğünde alır
+// ve sıkıştırır.
+func Capture(ctx context.Context, pc *client.PrometheusClient, metric string, trainDays int, step time.Duration) (*Snapshot, error) {
+	step = max(step, time.Second)
+	end := time.Now().Truncate(step)
+	start := end.Add(-time.Duration(trainDays) * 24 * time.Hour)
+
+	series := map[model.Fingerprint]*Series{}
+	chunk := step * chunkPoints
+	for from := start; !from.After(end); from = from.Add(chunk + step) {
+		to := minTime(from.Add(chunk), end)
+		matrix, err := pc.QueryRange(ctx, metric, from, to, step)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s..%s: %w", from.Format(time.RFC3339), to.Format(time.RFC3339), err)
+		}
+		for _, stream := range matrix {
+			fp := stream.Metric.Fingerprint()
+			s, ok := series[fp]
+			if !ok {
+				s = &Series{Labels: map[string]string{}, Values: [][2]float64{}}
+				for name, value := range stream.Metric {
+					s.Labels[string(name)] = string(value)
+				}
+				series[fp] = s
+			}
+			for _, sample := range stream.Values {
+				s.Values = append(s.Values, [2]float64{float64(sample.Timestamp.Unix()), float64(sample.Value)})
+			}
+		}
+	}
+
+	data := Data{Metric: metric, Start: start, End: end, StepSeconds: int64(step / time.Second), Series: []Series{}}
+	for _, s := range series {
+		data.Series = append(data.Series, *s)
+	}
+	// Aynı veri aynı hash'i versin diye seriler etiketlerine göre sıralanır
+	sort.Slice(data.Series, func(i, j int) bool {
+		return model.LabelsToSignature(data.Series[i].Labels) < model.LabelsToSignature(data.Series[j].Labels)
+	})
+	return Encode(data)
+}
+
+// Encode, veriyi JSON'a çevirip sıkıştırır ve hash'ini hesaplar.
+func Encode(data Data) (*Snapshot, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return nil, fmt.Errorf("failed to compress snapshot: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress snapshot: %w", err)
+	}
+
+	samples := 0
+	for _, s := range data.Series {
+		samples += len(s.Values)
+	}
+	sum := sha256.Sum256(raw)
+	return &Snapshot{
+		Data:             data,
+		Samples:          samples,
+		Hash:             "sha256:" + hex.EncodeToString(sum[:]),
+		UncompressedSize: len(raw),
+		Compressed:       buf.Bytes(),
+	}, nil
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,144 @@
+package snapshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"solar-scope/internal/client"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testData() Data {
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	return Data{
+		Metric:      "mppt_values",
+		Start:       start,
+		End:         start.Add(time.Hour),
+		StepSeconds: 1800,
+		Series: []Series{
+			{Labels: map[string]string{"sensor": "panel gucu"}, Values: [][2]float64{{1717200000, 410.5}, {1717201800, 0}}},
+		},
+	}
+}
+
+func TestEncode(t *testing.T) {
+	first, err := Encode(testData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Encode(testData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Hash != second.Hash || !strings.HasPrefix(first.Hash, "sha256:") {
+		t.Errorf("Hash = %q and %q, want the same sha256 hash", first.Hash, second.Hash)
+	}
+	if first.Samples != 2 {
+		t.Errorf("Samples = %d, want 2", first.Samples)
+	}
+
+	changed := testData()
+	changed.Series[0].Values[0][1] = 411
+	other, err := Encode(changed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.Hash == first.Hash {
+		t.Error("different data produced the same hash")
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(first.Compressed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw) != first.UncompressedSize {
+		t.Errorf("UncompressedSize = %d, want %d", first.UncompressedSize, len(raw))
+	}
+	var decoded Data
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, testData()) {
+		t.Errorf("decoded = %+v, want %+v", decoded, testData())
+	}
+}
+
+// rangeServer, her aralık sorgusu için [start, end] arasındaki her adımda
+// iki seri döndüren sahte bir VictoriaMetrics başlatır.
+func rangeServer(t *testing.T) (*client.PrometheusClient, *atomic.Int32) {
+	t.Helper()
+	var queries atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries.Add(1)
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		start, _ := strconv.ParseFloat(r.Form.Get("start"), 64)
+		end, _ := strconv.ParseFloat(r.Form.Get("end"), 64)
+		step, _ := strconv.ParseFloat(r.Form.Get("step"), 64)
+
+		var values []string
+		for ts := start; ts <= end; ts += step {
+			values = append(values, fmt.Sprintf(`[%d,"%d"]`, int64(ts), int64(ts)))
+		}
+		joined := strings.Join(values, ",")
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"matrix","result":[`+
+			`{"metric":{"sensor":"panel b"},"values":[%s]},`+
+			`{"metric":{"sensor":"panel a"},"values":[%s]}]}}`, joined, joined)
+	}))
+	t.Cleanup(server.Close)
+
+	pc, err := client.NewPrometheusClient(server.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pc, &queries
+}
+
+func TestCaptureChunks(t *testing.T) {
+	pc, queries := rangeServer(t)
+	step := time.Minute
+
+	// 15 gün dakikalık çözünürlükte üç parçaya bölünür
+	snap, err := Capture(context.Background(), pc, "mppt_values", 15, step)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := queries.Load(); got != 3 {
+		t.Errorf("range queries = %d, want 3", got)
+	}
+
+	want := int(snap.Data.End.Sub(snap.Data.Start)/step) + 1
+	if len(snap.Data.Series) != 2 {
+		t.Fatalf("series = %d, want 2", len(snap.Data.Series))
+	}
+	for _, s := range snap.Data.Series {
+		if len(s.Values) != want {
+			t.Errorf("%v: samples = %d, want %d", s.Labels, len(s.Values), want)
+		}
+		// Parçalar arasında tekrar eden veya atlanan adım olmamalı
+		for i, v := range s.Values {
+			if expected := float64(snap.Data.Start.Unix()) + float64(i)*step.Seconds(); v[0] != expected {
+				t.Fatalf("%v: sample %d at %v, want %v", s.Labels, i, v[0], expected)
+			}
+		}
+	}
+	if snap.Samples != 2*want {
+		t.Errorf("Samples = %d, want %d", snap.Samples, 2*want)
+	}
+}
@@ -0,0 +1,48 @@
+package solar
+
+import (
+	"math"
+	"time"
+)
+
+// solarConstant, atmosfer dışındaki ortalama ışınımdır (W/m²).
+const solarConstant = 1361
+
+// Irradiance, yatay düzlemdeki toplam (GHI), doğrudan normal (DNI) ve
+// yaygın (DHI) ışınımdır (W/m²).
+type Irradiance struct {
+	GHI float64 `json:"ghi"`
+	DNI float64 `json:"dni"`
+	DHI float64 `json:"dhi"`
+}
+
+// AirMass, Kasten-Young (1989) bağıl hava kütlesidir; güneş ufkun
+// altındaysa 0 döner.
+func AirMass(zenith float64) float64 {
+	if zenith >= 90 {
+		return 0
+	}
+	return 1 / (math.Cos(rad(zenith)) + 0.50572*math.Pow(96.07995-zenith, -1.6364))
+}
+
+// Extraterrestrial, t günündeki atmosfer dışı ışınımdır; Dünya-Güneş
+// uzaklığı yıl içinde değiştiğinden yaklaşık ±%3.3 salınır.
+func Extraterrestrial(t time.Time) float64 {
+	return solarConstant * (1 + 0.033*math.Cos(2*math.Pi*float64(t.YearDay())/365))
+}
+
+// ClearSky, açık gök ışınımını Meinel modeliyle ve Laue'nun yükseklik
+// düzeltmesiyle hesaplar (altitude metre). Yaygın ışınım doğrudan
+// ışınımın %10'u kabul edilir. Bulutsuz, ortalama bulanıklıkta bir gökyüzü
+// için kaba bir üst sınırdır.
+func ClearSky(pos Position, altitude float64) Irradiance {
+	am := AirMass(pos.Zenith)
+	if am == 0 {
+		return Irradiance{}
+	}
+	h := math.Max(0, altitude) / 1000
+	dni := Extraterrestrial(pos.Time) * ((1-0.14*h)*math.Pow(0.7, math.Pow(am, 0.678)) + 0.14*h)
+	dhi := 0.1 * dni
+	ghi := dni*math.Cos(rad(pos.Zenith)) + dhi
+	return Irradiance{GHI: round(ghi, 2), DNI: round(dni, 2), DHI: round(dhi, 2)}
+}
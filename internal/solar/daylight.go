@@ -0,0 +1,64 @@
+package solar
+
+import (
+	"math"
+	"time"
+)
+
+// Kutup bölgelerinde güneşin doğmadığı veya batmadığı günler.
+const (
+	PolarDay   = "polar_day"
+	PolarNight = "polar_night"
+)
+
+// sunriseZenith, gün doğumu ve batımının tepe açısıdır: güneş diskinin
+// yarıçapı ve ufuktaki ortalama kırılma için 90.833°.
+const sunriseZenith = 90.833
+
+// Daylight, bir günün gün doğumu, öğle ve batım zamanlarıdır. Polar
+// doluysa Sunrise ve Sunset nil'dir.
+type Daylight struct {
+	Date             string     `json:"date"`
+	Sunrise          *time.Time `json:"sunrise"`
+	SolarNoon        time.Time  `json:"solar_noon"`
+	Sunset           *time.Time `json:"sunset"`
+	DayLengthSeconds int64      `json:"day_length_seconds"`
+	Polar            string     `json:"polar,omitempty"`
+}
+
+// SunTimes, lat, lon'daki güneş gününün olaylarını hesaplar. date'in
+// yalnızca takvim günü kullanılır; zamanlar date'in saat diliminde döner.
+func SunTimes(lat, lon float64, date time.Time) Daylight {
+	loc := date.Location()
+	midnight := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	// Öğle önce boylamın ortalama güneş zamanıyla tahmin edilir, sonra o
+	// anın zaman denklemiyle düzeltilir
+	noon := midnight.Add(minutes(720 - 4*lon))
+	decl, eqTime := sunDeclination(noon)
+	noon = midnight.Add(minutes(720 - 4*lon - eqTime)).Round(time.Second)
+	decl, _ = sunDeclination(noon)
+
+	daylight := Daylight{Date: date.Format("2006-01-02"), SolarNoon: noon.In(loc)}
+	latR, declR := rad(lat), rad(decl)
+	cosHA := math.Cos(rad(sunriseZenith))/(math.Cos(latR)*math.Cos(declR)) - math.Tan(latR)*math.Tan(declR)
+	switch {
+	case cosHA > 1:
+		daylight.Polar = PolarNight
+		return daylight
+	case cosHA < -1:
+		daylight.Polar = PolarDay
+		daylight.DayLengthSeconds = 24 * 60 * 60
+		return daylight
+	}
+
+	halfDay := minutes(4 * deg(math.Acos(cosHA))).Round(time.Second)
+	sunrise, sunset := noon.Add(-halfDay).In(loc), noon.Add(halfDay).In(loc)
+	daylight.Sunrise, daylight.Sunset = &sunrise, &sunset
+	daylight.DayLengthSeconds = int64(sunset.Sub(sunrise) / time.Second)
+	return daylight
+}
+
+func minutes(m float64) time.Duration {
+	return time.Duration(m * float64(time.Minute))
+}
@@ -0,0 +1,129 @@
+// Package solar, güneşin konumunu, gün doğumu ve batımını ve açık gök
+// ışınımını hesaplar. Konum hesabı NOAA'nın güneş hesaplayıcısındaki
+// algoritmayı izler; 1800-2100 arasında açılarda yaklaşık 0.01° doğruluk
+// verir.
+package solar
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Position, güneşin bir yer ve andaki konumudur. Açılar derecedir; azimut
+// kuzeyden saat yönünde ölçülür.
+type Position struct {
+	Time time.Time `json:"time"`
+	// Elevation, atmosferik kırılma düzeltilmiş yükselme açısıdır.
+	Elevation float64 `json:"elevation"`
+	Azimuth   float64 `json:"azimuth"`
+	Zenith    float64 `json:"zenith"`
+	// Declination ve EquationOfTime (dakika), gün doğumu hesabında da kullanılır.
+	Declination    float64 `json:"declination"`
+	EquationOfTime float64 `json:"equation_of_time"`
+}
+
+// CheckCoordinates, enlem ve boylamın geçerli aralıkta olduğunu denetler.
+func CheckCoordinates(lat, lon float64) error {
+	if math.IsNaN(lat) || lat < -90 || lat > 90 {
+		return fmt.Errorf("latitude must be between -90 and 90")
+	}
+	if math.IsNaN(lon) || lon < -180 || lon > 180 {
+		return fmt.Errorf("longitude must be between -180 and 180")
+	}
+	return nil
+}
+
+// SunPosition, güneşin lat, lon'daki t anındaki konumunu hesaplar.
+func SunPosition(lat, lon float64, t time.Time) Position {
+	decl, eqTime := sunDeclination(t)
+
+	utc := t.UTC()
+	minutes := float64(utc.Hour()*60+utc.Minute()) + float64(utc.Second())/60 + float64(utc.Nanosecond())/6e10
+	trueSolarTime := math.Mod(minutes+eqTime+4*lon, 1440)
+	if trueSolarTime < 0 {
+		trueSolarTime += 1440
+	}
+	hourAngle := trueSolarTime/4 - 180
+
+	latR, declR := rad(lat), rad(decl)
+	cosZenith := math.Sin(latR)*math.Sin(declR) + math.Cos(latR)*math.Cos(declR)*math.Cos(rad(hourAngle))
+	zenith := deg(math.Acos(clamp(cosZenith)))
+
+	var azimuth float64
+	if denom := math.Cos(latR) * math.Sin(rad(zenith)); math.Abs(denom) > 1e-9 {
+		a := deg(math.Acos(clamp((math.Sin(latR)*math.Cos(rad(zenith)) - math.Sin(declR)) / denom)))
+		if hourAngle > 0 {
+			azimuth = math.Mod(a+180, 360)
+		} else {
+			azimuth = math.Mod(540-a, 360)
+		}
+	} else if lat > 0 {
+		azimuth = 180 // Güneş tam tepede veya kutupta; güneye bakıldığı varsayılır
+	}
+
+	elevation := 90 - zenith
+	elevation += refraction(elevation)
+	return Position{
+		Time:           t,
+		Elevation:      round(elevation, 4),
+		Azimuth:        round(azimuth, 4),
+		Zenith:         round(90-elevation, 4),
+		Declination:    round(decl, 4),
+		EquationOfTime: round(eqTime, 4),
+	}
+}
+
+// sunDeclination, t anındaki güneş deklinasyonunu (derece) ve zaman
+// denklemini (dakika) döner.
+func sunDeclination(t time.Time) (decl, eqTime float64) {
+	jd := float64(t.UnixNano())/86400e9 + 2440587.5
+	jc := (jd - 2451545) / 36525
+
+	meanLong := math.Mod(280.46646+jc*(36000.76983+jc*0.0003032), 360)
+	meanAnom := 357.52911 + jc*(35999.05029-0.0001537*jc)
+	eccent := 0.016708634 - jc*(0.000042037+0.0000001267*jc)
+	center := math.Sin(rad(meanAnom))*(1.914602-jc*(0.004817+0.000014*jc)) +
+		math.Sin(rad(2*meanAnom))*(0.019993-0.000101*jc) +
+		math.Sin(rad(3*meanAnom))*0.000289
+	appLong := meanLong + center - 0.00569 - 0.00478*math.Sin(rad(125.04-1934.136*jc))
+	meanObliq := 23 + (26+(21.448-jc*(46.815+jc*(0.00059-jc*0.001813)))/60)/60
+	obliq := meanObliq + 0.00256*math.Cos(rad(125.04-1934.136*jc))
+
+	decl = deg(math.Asin(math.Sin(rad(obliq)) * math.Sin(rad(appLong))))
+	y := math.Pow(math.Tan(rad(obliq/2)), 2)
+	eqTime = 4 * deg(y*math.Sin(2*rad(meanLong))-
+		2*eccent*math.Sin(rad(meanAnom))+
+		4*eccent*y*math.Sin(rad(meanAnom))*math.Cos(2*rad(meanLong))-
+		0.5*y*y*math.Sin(4*rad(meanLong))-
+		1.25*eccent*eccent*math.Sin(2*rad(meanAnom)))
+	return decl, eqTime
+}
+
+// refraction, NOAA'nın yükselme açısına eklediği atmosferik kırılma
+// düzeltmesidir (derece).
+func refraction(elevation float64) float64 {
+	te := math.Tan(rad(elevation))
+	var arcsec float64
+	switch {
+	case elevation > 85:
+		return 0
+	case elevation > 5:
+		arcsec = 58.1/te - 0.07/math.Pow(te, 3) + 0.000086/math.Pow(te, 5)
+	case elevation > -0.575:
+		arcsec = 1735 + elevation*(-518.2+elevation*(103.4+elevation*(-12.79+elevation*0.711)))
+	default:
+		arcsec = -20.772 / te
+	}
+	return arcsec / 3600
+}
+
+func rad(d float64) float64 { return d * math.Pi / 180 }
+func deg(r float64) float64 { return r * 180 / math.Pi }
+
+func clamp(v float64) float64 { return math.Max(-1, math.Min(1, v)) }
+
+func round(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
@@ -0,0 +1,147 @@
+package solar
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var istanbul = func() *time.Location {
+	loc, err := time.LoadLocation("Europe/Istanbul")
+	if err != nil {
+		return time.FixedZone("+03", 3*60*60)
+	}
+	return loc
+}()
+
+// near, iki zamanın tolerans içinde olduğunu denetler.
+func near(t *testing.T, name string, got, want time.Time, tolerance time.Duration) {
+	t.Helper()
+	if d := got.Sub(want); d < -tolerance || d > tolerance {
+		t.Errorf("%s = %s, want %s ± %s", name, got.Format(time.RFC3339), want.Format(time.RFC3339), tolerance)
+	}
+}
+
+// Referans değerler NOAA'nın güneş hesaplayıcısındandır (gml.noaa.gov/grad/solcalc).
+func TestSunTimesNOAA(t *testing.T) {
+	tests := []struct {
+		name                  string
+		lat, lon              float64
+		date                  time.Time
+		sunrise, noon, sunset time.Time
+	}{
+		{
+			"istanbul summer solstice", 41.0082, 28.9784, time.Date(2025, 6, 21, 0, 0, 0, 0, istanbul),
+			time.Date(2025, 6, 21, 5, 32, 0, 0, istanbul), time.Date(2025, 6, 21, 13, 6, 0, 0, istanbul), time.Date(2025, 6, 21, 20, 40, 0, 0, istanbul),
+		},
+		{
+			"istanbul winter solstice", 41.0082, 28.9784, time.Date(2025, 12, 21, 0, 0, 0, 0, istanbul),
+			time.Date(2025, 12, 21, 8, 25, 0, 0, istanbul), time.Date(2025, 12, 21, 13, 2, 0, 0, istanbul), time.Date(2025, 12, 21, 17, 39, 0, 0, istanbul),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := SunTimes(tt.lat, tt.lon, tt.date)
+			if d.Sunrise == nil || d.Sunset == nil || d.Polar != "" {
+				t.Fatalf("SunTimes() = %+v, want sunrise and sunset", d)
+			}
+			near(t, "sunrise", *d.Sunrise, tt.sunrise, 2*time.Minute)
+			near(t, "solar noon", d.SolarNoon, tt.noon, time.Minute)
+			near(t, "sunset", *d.Sunset, tt.sunset, 2*time.Minute)
+			if d.Sunrise.Location() != istanbul || d.Date != tt.date.Format("2006-01-02") {
+				t.Errorf("SunTimes() = %+v, want times in the date's location", d)
+			}
+			if got := time.Duration(d.DayLengthSeconds) * time.Second; got != d.Sunset.Sub(*d.Sunrise) {
+				t.Errorf("day length = %s, want sunset - sunrise", got)
+			}
+		})
+	}
+}
+
+func TestSolarNoonFollowsEquationOfTime(t *testing.T) {
+	// Zaman denkleminin yıllık uç değerleri: Şubat'ta yaklaşık -14.2,
+	// Kasım'da +16.4 dakika
+	feb := SunTimes(0, 0, time.Date(2025, 2, 11, 0, 0, 0, 0, time.UTC))
+	near(t, "february noon", feb.SolarNoon, time.Date(2025, 2, 11, 12, 14, 13, 0, time.UTC), 30*time.Second)
+	nov := SunTimes(0, 0, time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC))
+	near(t, "november noon", nov.SolarNoon, time.Date(2025, 11, 3, 11, 43, 30, 0, time.UTC), 30*time.Second)
+}
+
+func TestSunTimesPolar(t *testing.T) {
+	const lat, lon = 69.6492, 18.9553 // Tromsø
+	day := SunTimes(lat, lon, time.Date(2025, 6, 21, 0, 0, 0, 0, time.UTC))
+	if day.Polar != PolarDay || day.Sunrise != nil || day.Sunset != nil || day.DayLengthSeconds != 86400 {
+		t.Errorf("midsummer = %+v, want polar day", day)
+	}
+	night := SunTimes(lat, lon, time.Date(2025, 12, 21, 0, 0, 0, 0, time.UTC))
+	if night.Polar != PolarNight || night.Sunrise != nil || night.DayLengthSeconds != 0 {
+		t.Errorf("midwinter = %+v, want polar night", night)
+	}
+	if normal := SunTimes(lat, lon, time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC)); normal.Polar != "" || normal.Sunrise == nil {
+		t.Errorf("equinox = %+v, want sunrise and sunset", normal)
+	}
+}
+
+func TestSunPosition(t *testing.T) {
+	// Gündönümünde öğle yüksekliği 90 - enlem + eğiklik (23.44°) olur
+	noon := SunTimes(41.0082, 28.9784, time.Date(2025, 6, 21, 0, 0, 0, 0, istanbul)).SolarNoon
+	pos := SunPosition(41.0082, 28.9784, noon)
+	if math.Abs(pos.Declination-23.44) > 0.01 || math.Abs(pos.Elevation-(90-41.0082+23.44)) > 0.05 || math.Abs(pos.Azimuth-180) > 0.1 {
+		t.Errorf("solstice noon position = %+v", pos)
+	}
+	if math.Abs(pos.Zenith+pos.Elevation-90) > 1e-3 {
+		t.Errorf("zenith %v and elevation %v do not add up to 90", pos.Zenith, pos.Elevation)
+	}
+
+	// Ekinoks anında ekvatordaki güneş doğudan (sabah) veya batıdan (akşam) gelir
+	equinox := time.Date(2025, 3, 20, 9, 1, 0, 0, time.UTC)
+	morning := SunPosition(0, 0, equinox)
+	if math.Abs(morning.Declination) > 0.01 || math.Abs(morning.Azimuth-90) > 0.1 {
+		t.Errorf("equinox morning = %+v, want declination 0 and azimuth 90", morning)
+	}
+	if evening := SunPosition(0, 0, equinox.Add(6*time.Hour)); math.Abs(evening.Azimuth-270) > 0.5 {
+		t.Errorf("equinox afternoon = %+v, want azimuth 270", evening)
+	}
+	if night := SunPosition(41.0082, 28.9784, noon.Add(12*time.Hour)); night.Elevation > -20 {
+		t.Errorf("midnight elevation = %v, want the sun well below the horizon", night.Elevation)
+	}
+}
+
+func TestCheckCoordinates(t *testing.T) {
+	for _, c := range [][2]float64{{0, 0}, {90, 180}, {-90, -180}} {
+		if err := CheckCoordinates(c[0], c[1]); err != nil {
+			t.Errorf("CheckCoordinates(%v) = %v", c, err)
+		}
+	}
+	for _, c := range [][2]float64{{91, 0}, {0, -181}, {math.NaN(), 0}, {0, math.NaN()}} {
+		if err := CheckCoordinates(c[0], c[1]); err == nil {
+			t.Errorf("CheckCoordinates(%v) succeeded", c)
+		}
+	}
+}
+
+func TestClearSky(t *testing.T) {
+	at := time.Date(2025, 6, 21, 12, 0, 0, 0, time.UTC)
+	if got := ClearSky(Position{Time: at, Zenith: 95}, 0); got != (Irradiance{}) {
+		t.Errorf("ClearSky() below the horizon = %+v, want zero", got)
+	}
+	if am := AirMass(60); math.Abs(am-1.99) > 0.01 {
+		t.Errorf("AirMass(60) = %v, want about 1.99", am)
+	}
+
+	overhead := ClearSky(Position{Time: at, Zenith: 0}, 0)
+	// Haziran'da Dünya güneşe en uzak konumdadır: 1361 * 0.967 * 0.7
+	if math.Abs(overhead.DNI-921) > 5 || math.Abs(overhead.GHI-(overhead.DNI+overhead.DHI)) > 0.02 {
+		t.Errorf("ClearSky() overhead = %+v", overhead)
+	}
+	low := ClearSky(Position{Time: at, Zenith: 60}, 0)
+	if low.GHI >= overhead.GHI || math.Abs(low.GHI-(low.DNI*0.5+low.DHI)) > 0.02 {
+		t.Errorf("ClearSky() at 60° = %+v", low)
+	}
+	if high := ClearSky(Position{Time: at, Zenith: 60}, 2000); high.DNI <= low.DNI {
+		t.Errorf("ClearSky() at 2000 m = %+v, want more than at sea level %+v", high, low)
+	}
+	if jan := Extraterrestrial(time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)); jan < Extraterrestrial(at) {
+		t.Error("Extraterrestrial() should peak near perihelion in January")
+	}
+}
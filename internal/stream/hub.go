@@ -0,0 +1,197 @@
+package stream
+
+import (
+	"context"
+	"log/slog"
+	"solar-scope/internal/client"
+	"sync"
+	"time"
+)
+
+// Olay türleri
+const (
+	EventPanel    = "panel"
+	EventForecast = "forecast"
+)
+
+// Event, abonelere gönderilen tek bir olaydır.
+type Event struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// Scope, bir abonenin hangi tenant'ın verisini gördüğünü belirtir.
+// TenantID 0 ise abone tüm tenant'ları görür.
+type Scope struct {
+	TenantID   uint
+	LabelName  string
+	LabelValue string
+}
+
+// Subscriber, hub'a bağlı tek bir istemcidir.
+type Subscriber struct {
+	Events <-chan Event
+	events chan Event
+	scope  Scope
+}
+
+// topic, aynı scope'taki aboneleri ve onlar için çalışan tek poller'ı tutar.
+type topic struct {
+	subscribers map[*Subscriber]struct{}
+	cancel      context.CancelFunc
+	// latest, son panel olayıdır; yeni abonelere bir sonraki tick
+	// beklenmeden gönderilir.
+	latest *Event
+}
+
+// Hub, panel okumalarını scope başına tek bir poller ile sorgular ve tüm
+// abonelere dağıtır. Böylece açık dashboard sayısı VictoriaMetrics yükünü
+// artırmaz.
+type Hub struct {
+	vmClient *client.PrometheusClient
+	query    string
+	interval time.Duration
+
+	mu     sync.Mutex
+	topics map[uint]*topic
+	closed bool
+}
+
+// NewHub, verilen sorguyu interval aralıklarla çalıştıran bir hub oluşturur.
+func NewHub(vmClient *client.PrometheusClient, query string, interval time.Duration) *Hub {
+	return &Hub{
+		vmClient: vmClient,
+		query:    query,
+		interval: interval,
+		topics:   make(map[uint]*topic),
+	}
+}
+
+// Subscribe, scope için yeni bir abone ekler. Scope'un ilk abonesi poller'ı
+// başlatır; mevcut bir scope'a katılan abone son panel olayını hemen alır.
+// Tenant'ın metrik etiketi yapılandırılmamışsa hata döner.
+func (h *Hub) Subscribe(scope Scope) (*Subscriber, error) {
+	promClient := h.vmClient
+	if scope.TenantID != 0 {
+		scoped, err := h.vmClient.ForTenant(scope.LabelName, scope.LabelValue)
+		if err != nil {
+			return nil, err
+		}
+		promClient = scoped
+	}
+
+	events := make(chan Event, 16)
+	sub := &Subscriber{Events: events, events: events, scope: scope}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(events)
+		return sub, nil
+	}
+
+	t, ok := h.topics[scope.TenantID]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		t = &topic{subscribers: make(map[*Subscriber]struct{}), cancel: cancel}
+		h.topics[scope.TenantID] = t
+		go h.poll(ctx, promClient, scope.TenantID)
+	} else if t.latest != nil {
+		events <- *t.latest
+	}
+	t.subscribers[sub] = struct{}{}
+	return sub, nil
+}
+
+// Unsubscribe, aboneyi çıkarır. Scope'un son abonesi ayrılınca poller durur.
+func (h *Hub) Unsubscribe(sub *Subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	t, ok := h.topics[sub.scope.TenantID]
+	if !ok {
+		return
+	}
+	if _, ok := t.subscribers[sub]; !ok {
+		return
+	}
+	delete(t.subscribers, sub)
+	close(sub.events)
+	if len(t.subscribers) == 0 {
+		t.cancel()
+		delete(h.topics, sub.scope.TenantID)
+	}
+}
+
+// PublishForecast, kaydedilen bir tahmini tenant'ın abonelerine ve sistem
+// genelindeki abonelere gönderir.
+func (h *Hub) PublishForecast(tenantID uint, forecast interface{}) {
+	event := Event{Type: EventForecast, Data: forecast}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.broadcast(tenantID, event)
+	if tenantID != 0 {
+		h.broadcast(0, event)
+	}
+}
+
+// Close, tüm poller'ları durdurur ve abone kanallarını kapatır.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for tenantID, t := range h.topics {
+		t.cancel()
+		for sub := range t.subscribers {
+			close(sub.events)
+		}
+		delete(h.topics, tenantID)
+	}
+}
+
+// broadcast, olayı scope'un abonelerine gönderir. Kanalı dolu olan yavaş
+// aboneler bu olayı kaçırır; poller hiçbir abone için beklemez.
+// Çağıranın h.mu kilidini tutması gerekir.
+func (h *Hub) broadcast(tenantID uint, event Event) {
+	t, ok := h.topics[tenantID]
+	if !ok {
+		return
+	}
+	for sub := range t.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+		}
+	}
+}
+
+// poll, tenant'ın son panel okumalarını interval aralıklarla sorgular.
+func (h *Hub) poll(ctx context.Context, promClient *client.PrometheusClient, tenantID uint) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		result, err := promClient.Query(ctx, h.query)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Error("error polling panel metrics for stream", "tenant_id", tenantID, "error", err)
+			}
+		} else {
+			event := Event{Type: EventPanel, Data: result}
+			h.mu.Lock()
+			// İptal edilmemiş bir poller'ın topic'i hâlâ haritadadır
+			if ctx.Err() == nil {
+				h.topics[tenantID].latest = &event
+				h.broadcast(tenantID, event)
+			}
+			h.mu.Unlock()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
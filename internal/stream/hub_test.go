@@ -0,0 +1,187 @@
+package stream
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"solar-scope/internal/client"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const pollInterval = 20 * time.Millisecond
+
+// newTestHub, her sorguyu sayan sahte bir VictoriaMetrics'e bağlı hub döner.
+func newTestHub(t *testing.T) (*Hub, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	vm, err := client.NewPrometheusClient(server.URL, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hub := NewHub(vm, "panel_power", pollInterval)
+	t.Cleanup(hub.Close)
+	return hub, &hits
+}
+
+func subscribe(t *testing.T, hub *Hub, scope Scope) *Subscriber {
+	t.Helper()
+	sub, err := hub.Subscribe(scope)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	return sub
+}
+
+func topicCount(hub *Hub) int {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	return len(hub.topics)
+}
+
+// next, abonenin bir sonraki olayını bekler.
+func next(t *testing.T, sub *Subscriber) (Event, bool) {
+	t.Helper()
+	select {
+	case event, ok := <-sub.Events:
+		return event, ok
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+		return Event{}, false
+	}
+}
+
+func TestSinglePollerPerTopic(t *testing.T) {
+	hub, hits := newTestHub(t)
+
+	start := time.Now()
+	first := subscribe(t, hub, Scope{})
+	if event, _ := next(t, first); event.Type != EventPanel {
+		t.Fatalf("event type = %q, want %q", event.Type, EventPanel)
+	}
+	subs := []*Subscriber{first}
+	for i := 0; i < 4; i++ {
+		subs = append(subs, subscribe(t, hub, Scope{}))
+	}
+
+	time.Sleep(5 * pollInterval)
+	for _, sub := range subs {
+		next(t, sub)
+	}
+	// Tek poller her tick'te bir sorgu yapar; abone başına poller olsaydı
+	// sorgu sayısı beş katına çıkardı
+	ticks := int32(time.Since(start)/pollInterval) + 1
+	if got := hits.Load(); got > ticks {
+		t.Errorf("queries = %d, want at most %d", got, ticks)
+	}
+}
+
+func TestSubscribeSendsLatestSnapshot(t *testing.T) {
+	hub, _ := newTestHub(t)
+	hub.interval = time.Hour
+
+	first := subscribe(t, hub, Scope{})
+	next(t, first)
+
+	late := subscribe(t, hub, Scope{})
+	select {
+	case event := <-late.Events:
+		if event.Type != EventPanel {
+			t.Errorf("event type = %q, want %q", event.Type, EventPanel)
+		}
+	default:
+		t.Fatal("late subscriber did not receive the cached snapshot")
+	}
+}
+
+func TestSubscribeRejectsUnscopedTenant(t *testing.T) {
+	hub, hits := newTestHub(t)
+
+	if _, err := hub.Subscribe(Scope{TenantID: 7}); err == nil {
+		t.Fatal("Subscribe() succeeded without a tenant label")
+	}
+	if n := topicCount(hub); n != 0 {
+		t.Errorf("topics = %d, want 0", n)
+	}
+	time.Sleep(2 * pollInterval)
+	if got := hits.Load(); got != 0 {
+		t.Errorf("queries = %d, want 0", got)
+	}
+}
+
+func TestUnsubscribeStopsDelivery(t *testing.T) {
+	hub, hits := newTestHub(t)
+
+	sub := subscribe(t, hub, Scope{TenantID: 1, LabelName: "site", LabelValue: "a"})
+	next(t, sub)
+	hub.Unsubscribe(sub)
+
+	// Kanal kapanana kadar tamponda kalan olaylar okunabilir
+	for range sub.Events {
+	}
+	if n := topicCount(hub); n != 0 {
+		t.Errorf("topics = %d, want 0", n)
+	}
+	time.Sleep(pollInterval) // iptal anında yoldaki sorgunun bitmesini bekle
+	stopped := hits.Load()
+	time.Sleep(3 * pollInterval)
+	if got := hits.Load(); got != stopped {
+		t.Errorf("queries after last unsubscribe = %d, want %d", got, stopped)
+	}
+
+	hub.PublishForecast(1, "forecast")
+	hub.Unsubscribe(sub) // ikinci çağrı kanalı tekrar kapatmamalı
+}
+
+func TestPublishForecastReachesSystemSubscribers(t *testing.T) {
+	hub, _ := newTestHub(t)
+	hub.interval = time.Hour
+
+	system := subscribe(t, hub, Scope{})
+	tenant := subscribe(t, hub, Scope{TenantID: 1, LabelName: "site", LabelValue: "a"})
+	other := subscribe(t, hub, Scope{TenantID: 2, LabelName: "site", LabelValue: "b"})
+	for _, sub := range []*Subscriber{system, tenant, other} {
+		next(t, sub)
+	}
+
+	hub.PublishForecast(1, "forecast")
+	for _, sub := range []*Subscriber{system, tenant} {
+		if event, _ := next(t, sub); event.Type != EventForecast {
+			t.Errorf("event type = %q, want %q", event.Type, EventForecast)
+		}
+	}
+	select {
+	case event := <-other.Events:
+		t.Errorf("other tenant received %q event", event.Type)
+	default:
+	}
+}
+
+func TestCloseStopsPollers(t *testing.T) {
+	hub, hits := newTestHub(t)
+
+	sub := subscribe(t, hub, Scope{})
+	next(t, sub)
+	hub.Close()
+
+	for range sub.Events {
+	}
+	time.Sleep(pollInterval) // iptal anında yoldaki sorgunun bitmesini bekle
+	stopped := hits.Load()
+	time.Sleep(3 * pollInterval)
+	if got := hits.Load(); got != stopped {
+		t.Errorf("queries after Close = %d, want %d", got, stopped)
+	}
+
+	late := subscribe(t, hub, Scope{})
+	if _, ok := <-late.Events; ok {
+		t.Error("subscriber after Close received an event")
+	}
+}
@@ -0,0 +1,66 @@
+package stream
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"solar-scope/internal/apierror"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// heartbeatInterval, bağlantının proxy'ler tarafından kapatılmaması için
+// gönderilen yorum satırlarının aralığıdır.
+const heartbeatInterval = 15 * time.Second
+
+// ServeSSE, isteği Server-Sent Events akışına çevirir ve scope'un olaylarını
+// istemci bağlantıyı kapatana kadar gönderir.
+func (h *Hub) ServeSSE(c *fiber.Ctx, scope Scope) error {
+	sub, err := h.Subscribe(scope)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "error subscribing to stream", "tenant_id", scope.TenantID, "error", err)
+		return apierror.Write(c, fiber.StatusForbidden, apierror.CodeForbidden, "Tenant has no metric label configured")
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no") // nginx tamponlamasını kapat
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer h.Unsubscribe(sub)
+
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+
+		// İlk yorum, başlıkların hemen gönderilmesini sağlar
+		fmt.Fprint(w, ": connected\n\n")
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		for {
+			select {
+			case event, ok := <-sub.Events:
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(event.Data)
+				if err != nil {
+					slog.Error("error marshaling stream event", "type", event.Type, "error", err)
+					continue
+				}
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": heartbeat\n\n")
+			}
+			// Yazma hatası istemcinin bağlantıyı kapattığını gösterir
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+	return nil
+}
@@ -0,0 +1,105 @@
+package tenant
+
+import (
+	"fmt"
+	"os"
+	"solar-scope/internal/envfile"
+	"solar-scope/models"
+	"strings"
+
+	"github.com/VictoriaMetrics/metricsql"
+)
+
+// ScopeSelector, METRIC_NAME gibi bir metrik seçicisine tenant etiketini
+// ekler. İfade MetricsQL ayrıştırıcısıyla okunur ve yalnızca tek bir seri
+// seçicisi kabul edilir; or, unless, aritmetik, fonksiyon, aralık veya alt
+// sorgu içeren ifadeler reddedilir. Seçicinin içinde or ile ayrılmış her
+// filtre grubuna etiket ayrı ayrı eklenir. Bir grup tenant etiketine farklı
+// bir değer veya eşitlik dışında bir işleç uyguluyorsa hata döner.
+func ScopeSelector(selector string, t *models.Tenant) (string, error) {
+	expr, err := metricsql.Parse(selector)
+	if err != nil {
+		return "", fmt.Errorf("metric %q is not a valid selector: %w", selector, err)
+	}
+	me, ok := expr.(*metricsql.MetricExpr)
+	if !ok || len(me.LabelFilterss) == 0 {
+		return "", fmt.Errorf("metric %q must be a plain series selector", selector)
+	}
+
+	required := metricsql.LabelFilter{Label: t.LabelName, Value: t.LabelValue}
+	groups := make([][]metricsql.LabelFilter, 0, len(me.LabelFilterss))
+	for _, filters := range me.LabelFilterss {
+		scoped := make([]metricsql.LabelFilter, 0, len(filters)+1)
+		present := false
+		for _, lf := range filters {
+			if lf.Label != t.LabelName {
+				continue
+			}
+			if lf != required {
+				return "", fmt.Errorf("metric %q filters on %s outside the caller's tenant", selector, t.LabelName)
+			}
+			present = true
+		}
+		// Metrik adı filtresi her grupta ilk sırada kalmalıdır
+		offset := 0
+		if len(filters) > 0 && filters[0].Label == "__name__" {
+			offset = 1
+		}
+		scoped = append(scoped, filters[:offset]...)
+		if !present {
+			scoped = append(scoped, required)
+		}
+		scoped = append(scoped, filters[offset:]...)
+		groups = append(groups, scoped)
+	}
+	return string((&metricsql.MetricExpr{LabelFilterss: groups}).AppendString(nil)), nil
+}
+
+// ScopeOverrides, run-with-env override'larındaki METRIC_NAME'i tenant'a göre sınırlar.
+func ScopeOverrides(overrides map[string]interface{}, t *models.Tenant) error {
+	metric, ok := overrides["METRIC_NAME"]
+	if !ok {
+		return nil
+	}
+	selector, ok := metric.(string)
+	if !ok {
+		return fmt.Errorf("METRIC_NAME must be a string")
+	}
+	scoped, err := ScopeSelector(selector, t)
+	if err != nil {
+		return err
+	}
+	overrides["METRIC_NAME"] = scoped
+	return nil
+}
+
+// ScopeEnvFile, yüklenen .env dosyasındaki METRIC_NAME tanımlarını tenant'a
+// göre yeniden yazar. "export" önekli satırlar da dahil her tanım ayrı ayrı
+// sınırlanır; hangisi geçerli olursa olsun tenant dışına çıkamaz.
+// METRIC_NAME tanımlı değilse hata döner.
+func ScopeEnvFile(path string, t *models.Tenant) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read env file: %w", err)
+	}
+
+	lines := strings.Split(string(content), "\n")
+	found := false
+	for i, line := range lines {
+		key, value, ok := envfile.Assignment(line)
+		if !ok || key != "METRIC_NAME" {
+			continue
+		}
+		scoped, err := ScopeSelector(envfile.Unquote(value), t)
+		if err != nil {
+			return err
+		}
+		lines[i] = "METRIC_NAME=" + scoped
+		found = true
+	}
+	if !found {
+		return fmt.Errorf("env file must define METRIC_NAME")
+	}
+
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600)
+}
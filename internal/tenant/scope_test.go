@@ -0,0 +1,147 @@
+package tenant
+
+import (
+	"os"
+	"path/filepath"
+	"solar-scope/internal/envfile"
+	"solar-scope/models"
+	"strings"
+	"testing"
+)
+
+var mine = &models.Tenant{LabelName: "ilce", LabelValue: "mine"}
+
+func TestScopeSelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector string
+		want     string
+		wantErr  bool
+	}{
+		{"bare metric", `mppt_values`, `mppt_values{ilce="mine"}`, false},
+		{"with matchers", `mppt_values{sensor="panel gucu"}`, `mppt_values{ilce="mine",sensor="panel gucu"}`, false},
+		{"matchers only", `{sensor="x"}`, `{ilce="mine",sensor="x"}`, false},
+		{"own tenant kept", `mppt_values{ilce="mine",sensor="x"}`, `mppt_values{ilce="mine",sensor="x"}`, false},
+		{"or inside braces", `mppt_values{sensor="x" or sensor="y"}`, `mppt_values{ilce="mine",sensor="x" or ilce="mine",sensor="y"}`, false},
+		{"or inside braces with other tenant", `mppt_values{sensor="x" or ilce="other"}`, "", true},
+		{"conflicting matcher", `mppt_values{ilce="other"}`, "", true},
+		{"negative matcher", `mppt_values{ilce!="mine"}`, "", true},
+		{"regexp matcher", `mppt_values{ilce=~"mine|other"}`, "", true},
+		{"duplicate matcher", `mppt_values{ilce="mine",ilce=~".*"}`, "", true},
+		{"or", `mppt_values{sensor="x"} or mppt_values{ilce="other"}`, "", true},
+		{"unless", `mppt_values unless mppt_values{ilce="other"}`, "", true},
+		{"and", `mppt_values and on() mppt_values{ilce="other"}`, "", true},
+		{"binary operator", `mppt_values + mppt_values{ilce="other"}`, "", true},
+		{"scalar arithmetic", `mppt_values * 2`, "", true},
+		{"function", `sum(mppt_values)`, "", true},
+		{"rollup", `rate(mppt_values[5m])`, "", true},
+		{"range selector", `mppt_values[5m]`, "", true},
+		{"offset", `mppt_values offset 1h`, "", true},
+		{"subquery", `max_over_time(mppt_values[1h:5m])`, "", true},
+		{"with template", `with (x = mppt_values{ilce="other"}) x`, "", true},
+		{"number", `42`, "", true},
+		{"invalid", `mppt_values{`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ScopeSelector(tt.selector, mine)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ScopeSelector(%q) = %q, want error", tt.selector, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ScopeSelector(%q) error: %v", tt.selector, err)
+			}
+			if got != tt.want {
+				t.Errorf("ScopeSelector(%q) = %q, want %q", tt.selector, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScopeOverrides(t *testing.T) {
+	overrides := map[string]interface{}{"METRIC_NAME": `mppt_values{sensor="x"}`}
+	if err := ScopeOverrides(overrides, mine); err != nil {
+		t.Fatal(err)
+	}
+	if got := overrides["METRIC_NAME"]; got != `mppt_values{ilce="mine",sensor="x"}` {
+		t.Errorf("METRIC_NAME = %q", got)
+	}
+
+	if err := ScopeOverrides(map[string]interface{}{"METRIC_NAME": 5}, mine); err == nil {
+		t.Error("non-string METRIC_NAME accepted")
+	}
+	if err := ScopeOverrides(map[string]interface{}{"METRIC_NAME": `a or b{ilce="other"}`}, mine); err == nil {
+		t.Error("or expression accepted")
+	}
+	if err := ScopeOverrides(map[string]interface{}{"TRAIN_DAYS": 7}, mine); err != nil {
+		t.Errorf("overrides without METRIC_NAME: %v", err)
+	}
+}
+
+func TestScopeEnvFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "plain",
+			content: "TRAIN_DAYS=7\nMETRIC_NAME=mppt_values\n",
+			want:    "TRAIN_DAYS=7\nMETRIC_NAME=mppt_values{ilce=\"mine\"}\n",
+		},
+		{
+			name:    "quoted",
+			content: "METRIC_NAME='mppt_values{sensor=\"x\"}'\n",
+			want:    "METRIC_NAME=mppt_values{ilce=\"mine\",sensor=\"x\"}\n",
+		},
+		{
+			name:    "every assignment is scoped",
+			content: "METRIC_NAME=a\nexport METRIC_NAME=b\n  export\tMETRIC_NAME = c\n# METRIC_NAME=ignored\n",
+			want:    "METRIC_NAME=a{ilce=\"mine\"}\nMETRIC_NAME=b{ilce=\"mine\"}\nMETRIC_NAME=c{ilce=\"mine\"}\n# METRIC_NAME=ignored\n",
+		},
+		{
+			name:    "exported assignment outside the tenant",
+			content: "METRIC_NAME=x\nexport METRIC_NAME=x{ilce=\"other\"}\n",
+			wantErr: true,
+		},
+		{
+			name:    "compound expression",
+			content: "METRIC_NAME=x or y{ilce=\"other\"}\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing",
+			content: "TRAIN_DAYS=7\n# METRIC_NAME=x\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".env")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			err := ScopeEnvFile(path, mine)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _ := os.ReadFile(path)
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if metric := envfile.Parse(got)["METRIC_NAME"]; metric == "" || !strings.Contains(metric, `ilce="mine"`) {
+				t.Errorf("effective METRIC_NAME %q is not scoped", metric)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"solar-scope/internal/logging"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// headerCarrier, fasthttp istek başlıklarını propagator'a açar.
+type headerCarrier struct {
+	c *fiber.Ctx
+}
+
+func (hc headerCarrier) Get(key string) string { return hc.c.Get(key) }
+func (hc headerCarrier) Set(key, value string) { hc.c.Request().Header.Set(key, value) }
+func (hc headerCarrier) Keys() []string {
+	var keys []string
+	hc.c.Request().Header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
+
+// Middleware, her istek için bir sunucu span'i açar ve span'i UserContext'e
+// ekler. Gelen traceparent başlığı varsa span o trace'in devamı olur.
+// logging.Middleware'den sonra eklenmelidir.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), headerCarrier{c})
+		ctx, span := Tracer().Start(ctx, c.Method()+" "+c.Path(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Method()),
+				semconv.URLPath(c.Path()),
+			),
+		)
+		defer span.End()
+
+		if id := logging.RequestID(ctx); id != "" {
+			span.SetAttributes(attribute.String("request.id", id))
+		}
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		// Rota ve parametreler eşleşmeden sonra bilinir
+		route := c.Route().Path
+		span.SetName(c.Method() + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route))
+		if sessionID := c.Params("session_id"); sessionID != "" {
+			span.SetAttributes(attribute.String("session.id", sessionID))
+		}
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			if fiberErr, ok := err.(*fiber.Error); ok {
+				status = fiberErr.Code
+			} else {
+				status = fiber.StatusInternalServerError
+			}
+			span.RecordError(err)
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, "")
+		}
+		return err
+	}
+}
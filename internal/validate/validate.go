@@ -0,0 +1,161 @@
+package validate
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/VictoriaMetrics/metricsql"
+)
+
+// FieldError, tek bir alanın doğrulama hatasıdır. Field, alanın JSON adıdır.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Struct, v'nin alanlarını `validate` etiketlerine göre denetler. Kurallar
+// virgülle ayrılır:
+//
+//	required  sıfır değer olamaz
+//	min=N     sayı en az N olmalıdır
+//	max=N     sayı en çok N olmalıdır
+//	gt=N      sayı N'den büyük olmalıdır
+//	url       http veya https URL'si olmalıdır
+//	promql    geçerli bir PromQL/MetricsQL ifadesi olmalıdır
+//	oneof=A B metin boşlukla ayrılmış değerlerden biri olmalıdır
+//
+// Boş metin alanlarında ve nil işaretçilerde required dışındaki kurallar
+// atlanır; dolu işaretçilerde kurallar gösterilen değere uygulanır. Gömülü
+// struct'ların alanları da denetlenir. v bir struct veya struct işaretçisi
+// olmalıdır.
+func Struct(v interface{}) []FieldError {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	rt := rv.Type()
+	var errs []FieldError
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.Anonymous && field.IsExported() && field.Type.Kind() == reflect.Struct {
+			errs = append(errs, Struct(rv.Field(i).Interface())...)
+			continue
+		}
+		tag := field.Tag.Get("validate")
+		if tag == "" || !field.IsExported() {
+			continue
+		}
+		name := field.Name
+		if jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ","); jsonName != "" && jsonName != "-" {
+			name = jsonName
+		}
+		if msg := rules(rv.Field(i), tag); msg != "" {
+			errs = append(errs, FieldError{Field: name, Message: msg})
+		}
+	}
+	return errs
+}
+
+// Value, tek bir değeri Struct'ın etiket kurallarıyla denetler ve ilk hatanın
+// iletisini döner; değer geçerliyse veya etiket boşsa boş metin döner.
+func Value(v interface{}, tag string) string {
+	if tag == "" {
+		return ""
+	}
+	return rules(reflect.ValueOf(v), tag)
+}
+
+func rules(value reflect.Value, tag string) string {
+	for _, rule := range strings.Split(tag, ",") {
+		if msg := check(value, rule); msg != "" {
+			return msg // Alan başına ilk hata yeterlidir
+		}
+	}
+	return ""
+}
+
+func check(value reflect.Value, rule string) string {
+	name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+	if name == "required" {
+		if value.IsZero() {
+			return "is required"
+		}
+		return ""
+	}
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.String && value.String() == "" {
+		return ""
+	}
+
+	switch name {
+	case "min", "max", "gt":
+		limit, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			panic(fmt.Sprintf("validate: invalid %s rule %q", name, rule))
+		}
+		number, ok := numeric(value)
+		if !ok {
+			panic(fmt.Sprintf("validate: %s rule on non-numeric field", name))
+		}
+		switch {
+		case name == "min" && number < limit:
+			return fmt.Sprintf("must be at least %g", limit)
+		case name == "max" && number > limit:
+			return fmt.Sprintf("must be at most %g", limit)
+		case name == "gt" && number <= limit:
+			return fmt.Sprintf("must be greater than %g", limit)
+		}
+	case "url":
+		if err := URL(value.String()); err != nil {
+			return err.Error()
+		}
+	case "promql":
+		if err := PromQL(value.String()); err != nil {
+			return err.Error()
+		}
+	case "oneof":
+		options := strings.Fields(arg)
+		if !slices.Contains(options, value.String()) {
+			return "must be one of " + strings.Join(options, ", ")
+		}
+	default:
+		panic(fmt.Sprintf("validate: unknown rule %q", rule))
+	}
+	return ""
+}
+
+func numeric(value reflect.Value) (float64, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	}
+	return 0, false
+}
+
+// URL, değerin http veya https URL'si olduğunu denetler.
+func URL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("must be an http or https URL")
+	}
+	return nil
+}
+
+// PromQL, ifadenin sözdizimini denetler. VictoriaMetrics'in MetricsQL
+// ayrıştırıcısı kullanılır; MetricsQL PromQL'i kapsar.
+func PromQL(expr string) error {
+	if _, err := metricsql.Parse(expr); err != nil {
+		return fmt.Errorf("is not a valid PromQL expression: %v", err)
+	}
+	return nil
+}
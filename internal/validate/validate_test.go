@@ -0,0 +1,86 @@
+package validate
+
+import (
+	"reflect"
+	"testing"
+)
+
+type Inner struct {
+	Days int `json:"DAYS" validate:"min=1,max=365"`
+}
+
+type payload struct {
+	URL    string   `json:"URL" validate:"required,url"`
+	Query  string   `json:"QUERY,omitempty" validate:"promql"`
+	Ratio  *float64 `json:"ratio" validate:"gt=0,max=1"`
+	Method string   `validate:"oneof=a b"`
+	Note   string   `json:"note"`
+	Inner
+}
+
+func TestStruct(t *testing.T) {
+	ratio := func(v float64) *float64 { return &v }
+	valid := func() payload {
+		return payload{URL: "https://vm:8428", Query: `up{job="x"}`, Ratio: ratio(0.5), Method: "a", Inner: Inner{Days: 7}}
+	}
+	tests := []struct {
+		name   string
+		modify func(*payload)
+		want   []FieldError
+	}{
+		{"valid", func(*payload) {}, nil},
+		{"optional fields empty", func(p *payload) { p.Query, p.Ratio, p.Method = "", nil, "" }, nil},
+		{"required", func(p *payload) { p.URL = "" }, []FieldError{{"URL", "is required"}}},
+		{"first error only", func(p *payload) { p.Ratio = ratio(0) }, []FieldError{{"ratio", "must be greater than 0"}}},
+		{"url", func(p *payload) { p.URL = "vm:8428" }, []FieldError{{"URL", "must be an http or https URL"}}},
+		{"max through pointer", func(p *payload) { p.Ratio = ratio(1.5) }, []FieldError{{"ratio", "must be at most 1"}}},
+		{"oneof", func(p *payload) { p.Method = "c" }, []FieldError{{"Method", "must be one of a, b"}}},
+		{"embedded", func(p *payload) { p.Days = 0 }, []FieldError{{"DAYS", "must be at least 1"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid()
+			tt.modify(&p)
+			if got := Struct(&p); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Struct() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	p := valid()
+	p.Query = "sum(("
+	if errs := Struct(p); len(errs) != 1 || errs[0].Field != "QUERY" {
+		t.Errorf("Struct() = %v, want one QUERY error", errs)
+	}
+}
+
+func TestValue(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		tag   string
+		want  string
+	}{
+		{7, "min=1,max=365", ""},
+		{0, "min=1,max=365", "must be at least 1"},
+		{400, "min=1,max=365", "must be at most 365"},
+		{0.0, "gt=0", "must be greater than 0"},
+		{"", "required", "is required"},
+		{"", "url", ""},
+		{"http://vm", "required,url", ""},
+		{true, "", ""},
+	}
+	for _, tt := range tests {
+		if got := Value(tt.value, tt.tag); got != tt.want {
+			t.Errorf("Value(%v, %q) = %q, want %q", tt.value, tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestUnknownRulePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Value() with an unknown rule did not panic")
+		}
+	}()
+	Value("x", "email")
+}
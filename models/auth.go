@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APIKey, API erişim anahtarlarını temsil eder. Anahtarın kendisi saklanmaz,
+// yalnızca SHA-256 özeti tutulur.
+type APIKey struct {
+	gorm.Model
+	TenantID   uint       `json:"tenant_id" gorm:"index"` // 0: tüm tenant'lar
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-" gorm:"uniqueIndex;not null"`
+	Role       string     `json:"role" gorm:"not null"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "gorm.io/gorm"
+
+// EnvTemplate, yer tutucular içeren bir .env şablonunun tek bir sürümüdür.
+// Sürümler değiştirilmez; aynı adla kaydedilen her şablon yeni bir sürüm
+// olur ve şablondan oluşturulan session'lar sürümün ID'sini tutar.
+type EnvTemplate struct {
+	gorm.Model
+	TenantID    uint   `json:"tenant_id" gorm:"not null;uniqueIndex:idx_env_template_version"`
+	Name        string `json:"name" gorm:"not null;uniqueIndex:idx_env_template_version"`
+	Version     int    `json:"version" gorm:"not null;uniqueIndex:idx_env_template_version"`
+	Description string `json:"description"`
+	Content     string `json:"content" gorm:"type:text;not null"`
+	CreatedBy   string `json:"created_by"`
+}
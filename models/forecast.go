@@ -9,10 +9,13 @@ import (
 // Forecast ana tabloyu temsil eder. Bütün ilişkiler bu model üzerinden kurulur.
 type Forecast struct {
 	gorm.Model
+	TenantID              uint      `json:"tenant_id" gorm:"index"`
 	SessionID             string    `json:"session_id"`
 	Timestamp             time.Time `json:"timestamp"`
 	ForecastDate          string    `json:"date"`
 	GeneralStatus         string    `json:"general_status"`
+	SnapshotID            *uint     `json:"snapshot_id"`                      // Eğitim verisinin anlık görüntüsü, alındıysa
+	Provider              string    `json:"provider" gorm:"index;default:ml"` // Tahmini üreten sağlayıcı
 	EnergyBalance         EnergyBalance
 	BatteryPerformance    BatteryPerformance
 	ActionRecommendations []ActionRecommendation `gorm:"constraint:OnDelete:CASCADE;"`
@@ -72,4 +75,8 @@ type ForecastPayload struct {
 	GeneralStatus string `json:"general_status"`
 	SessionID     string `json:"session_id"`
 	Timestamp     string `json:"timestamp"`
+
+	// SnapshotID ve Provider, yanıta API tarafından eklenir; forecaster'dan gelmez
+	SnapshotID *uint  `json:"snapshot_id"`
+	Provider   string `json:"provider"`
 }
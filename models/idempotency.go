@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// IdempotencyKey, Idempotency-Key başlığıyla gelen bir isteğin yanıtını
+// saklar. StatusCode 0 iken istek hâlâ işlenmektedir.
+type IdempotencyKey struct {
+	gorm.Model
+	TenantID    uint      `json:"tenant_id" gorm:"uniqueIndex:idx_idempotency_tenant_key"`
+	Key         string    `json:"key" gorm:"uniqueIndex:idx_idempotency_tenant_key;size:255;not null"`
+	RequestHash string    `json:"-" gorm:"not null"`
+	StatusCode  int       `json:"status_code"`
+	ContentType string    `json:"-"`
+	Response    []byte    `json:"-"`
+	ExpiresAt   time.Time `json:"expires_at" gorm:"index"`
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Tenant, tek bir kurulumu paylaşan ilçe veya müşteriyi temsil eder.
+// LabelName/LabelValue, tenant'a ait metrikleri VictoriaMetrics'te ayıran
+// etikettir (örn. ilce="kadikoy").
+type Tenant struct {
+	gorm.Model
+	Name       string `json:"name" gorm:"not null"`
+	Slug       string `json:"slug" gorm:"uniqueIndex;not null"`
+	LabelName  string `json:"label_name" gorm:"not null"`
+	LabelValue string `json:"label_value" gorm:"not null"`
+	// SessionTTLSeconds, tenant'ın kullanılmayan forecaster session'larının
+	// silinmeden önce bekleyeceği süredir; 0 ise varsayılan süre geçerlidir.
+	SessionTTLSeconds int64 `json:"session_ttl_seconds"`
+}
+
+// Site, bir tenant'a ait panel sahasını temsil eder.
+type Site struct {
+	gorm.Model
+	TenantID    uint   `json:"tenant_id" gorm:"index;not null"`
+	Name        string `json:"name" gorm:"not null"`
+	Description string `json:"description"`
+	// Params, env şablonlarındaki yer tutucular için sahaya özel değerlerdir
+	// (örn. battery_capacity_wh).
+	Params datatypes.JSONMap `json:"params"`
+	// PV, beklenen güç hesabı için panel dizisinin konumu ve geometrisidir.
+	PV PVSystem `json:"pv" gorm:"embedded;embeddedPrefix:pv_"`
+}
+
+// PVSystem, bir sahanın panel dizisidir. Sıfır bırakılan kayıp ve verim
+// alanları için PVWatts varsayılanları kullanılır.
+type PVSystem struct {
+	Latitude    *float64 `json:"latitude" validate:"required,min=-90,max=90"`
+	Longitude   *float64 `json:"longitude" validate:"required,min=-180,max=180"`
+	AltitudeM   float64  `json:"altitude_m" validate:"min=-500,max=9000"`
+	CapacityKWp float64  `json:"capacity_kwp" gorm:"column:capacity_kwp" validate:"gt=0"`
+	TiltDeg     float64  `json:"tilt_deg" validate:"min=0,max=90"`
+	// AzimuthDeg kuzeyden saat yönündedir; verilmezse panel ekvatora bakar.
+	AzimuthDeg         *float64 `json:"azimuth_deg" validate:"min=0,max=360"`
+	TempCoefficient    float64  `json:"temp_coefficient" validate:"min=-2,max=0"`                     // %/°C, varsayılan -0.37
+	SystemLosses       float64  `json:"system_losses" validate:"min=0,max=99"`                        // %, varsayılan 14
+	InverterEfficiency float64  `json:"inverter_efficiency" validate:"min=0,max=100"`                 // %, varsayılan 96
+	DCACRatio          float64  `json:"dc_ac_ratio" gorm:"column:dc_ac_ratio" validate:"min=0,max=3"` // Varsayılan 1.2
+	// ProductionMetric, sahanın ölçülen AC gücüdür (W); boşsa panel gücü.
+	ProductionMetric string `json:"production_metric" validate:"promql"`
+	// IrradianceMetric, ölçülen yatay ışınımdır (W/m²); boşsa açık gök
+	// ışınımı kullanılır.
+	IrradianceMetric string `json:"irradiance_metric" validate:"promql"`
+}
+
+// Configured, beklenen güç hesabı için konumun ve gücün girilip
+// girilmediğini söyler.
+func (p PVSystem) Configured() bool {
+	return p.Latitude != nil && p.Longitude != nil && p.CapacityKWp > 0
+}
+
+// Session senkronizasyon durumları.
+const (
+	SessionSynced       = "synced"        // Hem yerelde hem upstream'de var
+	SessionLocalOnly    = "local_only"    // Upstream listesinde yok (örn. forecaster yeniden başladı)
+	SessionUpstreamOnly = "upstream_only" // solar-scope dışında oluşturulmuş
+)
+
+// ForecasterSession, SolarForecaster'da oluşturulan bir session'ın yerel
+// kaydıdır: kime ait olduğu, hangi saha için yüklendiği ve yüklenen env
+// dosyasının gizli değerleri maskelenmiş kopyası. Kayıtlar upstream session
+// listesiyle düzenli olarak karşılaştırılır.
+type ForecasterSession struct {
+	gorm.Model
+	SessionID  string            `json:"session_id" gorm:"uniqueIndex;not null"`
+	TenantID   uint              `json:"tenant_id" gorm:"index"`
+	Owner      string            `json:"owner"` // Yükleyen kimliğin subject'i
+	SiteID     *uint             `json:"site_id" gorm:"index"`
+	Backend    string            `json:"backend"` // Session'ı tutan forecaster
+	Params     datatypes.JSONMap `json:"params"`
+	LastUsedAt *time.Time        `json:"last_used_at"`
+	LastSeenAt *time.Time        `json:"last_seen_at"` // Upstream listesinde son görüldüğü an
+	SyncState  string            `json:"sync_state" gorm:"index;default:synced"`
+	TTLSeconds int64             `json:"ttl_seconds"`              // 0 ise tenant'ın veya varsayılan süre geçerlidir
+	TemplateID *uint             `json:"template_id" gorm:"index"` // Session bir env şablonundan oluşturulduysa şablon sürümü
+}
+
+// LastActivity, session'ın son kullanıldığı, hiç kullanılmadıysa oluşturulduğu
+// anı döner.
+func (s ForecasterSession) LastActivity() time.Time {
+	if s.LastUsedAt != nil && s.LastUsedAt.After(s.CreatedAt) {
+		return *s.LastUsedAt
+	}
+	return s.CreatedAt
+}
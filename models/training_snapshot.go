@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TrainingSnapshot, bir tahminin eğitildiği pencerenin sıkıştırılmış
+// kopyasıdır. VictoriaMetrics'in saklama süresi ve geç gelen örnekler geçmişi
+// değiştirdiğinden tahmin sonradan bu veriyle yeniden üretilebilir. Aynı
+// tenant'ta aynı içerik bir kez saklanır.
+type TrainingSnapshot struct {
+	gorm.Model
+	TenantID    uint      `json:"tenant_id" gorm:"not null;uniqueIndex:idx_snapshot_hash"`
+	ContentHash string    `json:"content_hash" gorm:"not null;uniqueIndex:idx_snapshot_hash"`
+	Metric      string    `json:"metric"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	StepSeconds int64     `json:"step_seconds"`
+	Series      int       `json:"series"`
+	Samples     int       `json:"samples"`
+	Encoding    string    `json:"encoding"`
+	Size        int       `json:"size"`        // Sıkıştırılmamış boyut (bayt)
+	StoredSize  int       `json:"stored_size"` // Sıkıştırılmış boyut (bayt)
+	Data        []byte    `json:"-" gorm:"not null"`
+}